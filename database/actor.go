@@ -0,0 +1,17 @@
+package database
+
+import (
+	"context"
+
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+// NewContextWithActorID stores the user or service the writes of ctx are made by
+func NewContextWithActorID(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, constants.ContextKeyActorID, actorID)
+}
+
+func GetActorID(ctx context.Context) string {
+	actorID, _ := ctx.Value(constants.ContextKeyActorID).(string)
+	return actorID
+}
@@ -0,0 +1,69 @@
+package database
+
+import "github.com/dotrongnhan/sharing-package/pkg/constants"
+
+// Aggregate is an aggregate function of a column, selected as Alias
+type Aggregate struct {
+	Func  string // "count", "sum", "avg", "min", "max"
+	Field string // a column, or "*" for count
+	Alias string // defaults to <func>_<field>, or count for count(*)
+}
+
+// AggregateCondition groups the rows matching the embedded CommonCondition by GroupBy and
+// computes Aggregates over each group. Having filters the groups, its fields are aggregate
+// aliases or grouped columns. Sorting may use the aliases too.
+type AggregateCondition struct {
+	CommonCondition
+	GroupBy    []string
+	Aggregates []Aggregate
+	Having     []Condition
+}
+
+func NewAggregateCondition() *AggregateCondition {
+	return &AggregateCondition{
+		CommonCondition: *NewCommonCondition(),
+	}
+}
+
+func (ac *AggregateCondition) WithGroupBy(fields ...string) *AggregateCondition {
+	ac.GroupBy = append(ac.GroupBy, fields...)
+	return ac
+}
+
+func (ac *AggregateCondition) WithAggregate(fn, field, alias string) *AggregateCondition {
+	ac.Aggregates = append(ac.Aggregates, Aggregate{
+		Func:  fn,
+		Field: field,
+		Alias: alias,
+	})
+	return ac
+}
+
+func (ac *AggregateCondition) Count(field, alias string) *AggregateCondition {
+	return ac.WithAggregate(constants.Count, field, alias)
+}
+
+func (ac *AggregateCondition) Sum(field, alias string) *AggregateCondition {
+	return ac.WithAggregate(constants.Sum, field, alias)
+}
+
+func (ac *AggregateCondition) Avg(field, alias string) *AggregateCondition {
+	return ac.WithAggregate(constants.Avg, field, alias)
+}
+
+func (ac *AggregateCondition) Min(field, alias string) *AggregateCondition {
+	return ac.WithAggregate(constants.Min, field, alias)
+}
+
+func (ac *AggregateCondition) Max(field, alias string) *AggregateCondition {
+	return ac.WithAggregate(constants.Max, field, alias)
+}
+
+func (ac *AggregateCondition) WithHaving(field string, value interface{}, op string) *AggregateCondition {
+	ac.Having = append(ac.Having, Condition{
+		Field: field,
+		Value: value,
+		Op:    op,
+	})
+	return ac
+}
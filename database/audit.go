@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+// AuditRecord is a change made to an entity. Before and After hold the JSON of the entity,
+// ChangedFields the JSON array of the keys whose value changed.
+type AuditRecord struct {
+	ID            string    `db:"id" omit:"true" json:"id"`
+	EntityTable   string    `db:"entity_table" json:"entity_table"`
+	EntityID      string    `db:"entity_id" json:"entity_id"`
+	Action        string    `db:"action" json:"action"`
+	ActorID       string    `db:"actor_id" json:"actor_id"`
+	TraceID       string    `db:"trace_id" json:"trace_id"`
+	Before        []byte    `db:"before" json:"before"`
+	After         []byte    `db:"after" json:"after"`
+	ChangedFields []byte    `db:"changed_fields" json:"changed_fields"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+type AuditStore interface {
+	// Record stores records, in the context transaction when there is one
+	Record(ctx context.Context, records ...*AuditRecord) error
+	// GetHistory returns the changes of an entity, oldest first
+	GetHistory(ctx context.Context, table string, id string) ([]*AuditRecord, error)
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 )
 
 type BaseRepository[T any] interface {
@@ -9,6 +10,8 @@ type BaseRepository[T any] interface {
 	GetByCondition(ctx context.Context, condition *CommonCondition) (*Pagination[T], error)
 	GetMany(ctx context.Context, condition *CommonCondition) ([]*T, error)
 	GetById(ctx context.Context, id string) (*T, error)
+	// GetByIdWith is GetById loading the relations named by preloads, see CommonCondition.Preload
+	GetByIdWith(ctx context.Context, id string, preloads ...string) (*T, error)
 	GetByIds(ctx context.Context, ids []string) ([]*T, error)
 	Create(ctx context.Context, entity *T) (*T, error)
 	CreateMany(ctx context.Context, entity []*T) ([]string, error)
@@ -17,4 +20,8 @@ type BaseRepository[T any] interface {
 	DeleteByCondition(ctx context.Context, condition *CommonCondition) error
 	DeleteMany(ctx context.Context, ids []string) error
 	ExistById(ctx context.Context, id string) (bool, error)
+	HardDelete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	GetDeleted(ctx context.Context, condition *CommonCondition) ([]*T, error)
+	PurgeDeleted(ctx context.Context, before time.Time) error
 }
@@ -0,0 +1,15 @@
+package database
+
+import "time"
+
+// Base holds the columns filled by the repositories: embed it in an entity to get the
+// id, the timestamps, the actors and soft delete
+type Base struct {
+	ID        string     `db:"id" omit:"true" json:"id"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	CreatedBy *string    `db:"created_by" json:"created_by"`
+	UpdatedBy *string    `db:"updated_by" json:"updated_by"`
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at"`
+	DeletedBy *string    `db:"deleted_by" json:"deleted_by"`
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// IdempotencyStatusInProgress marks a request that is still being executed
+	IdempotencyStatusInProgress = "in_progress"
+	// IdempotencyStatusCompleted marks a request whose response has been stored
+	IdempotencyStatusCompleted = "completed"
+)
+
+// ErrIdempotencyKeyExists is returned by IdempotencyStore.Acquire when a record
+// with the same key has already been stored
+var ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+
+type IdempotencyRecord struct {
+	ID          string     `db:"id" omit:"true"`
+	Key         string     `db:"key"`
+	Fingerprint string     `db:"fingerprint"`
+	Status      string     `db:"status"`
+	Response    []byte     `db:"response"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	DeletedAt   *time.Time `db:"deleted_at"`
+}
+
+type IdempotencyStore interface {
+	// Get returns the record stored for key, or nil when there is none
+	Get(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// Acquire stores an in-progress record for key in its own transaction, returning
+	// ErrIdempotencyKeyExists when another request already holds it
+	Acquire(ctx context.Context, key string, fingerprint string) (*IdempotencyRecord, error)
+	// Reclaim takes over the in-progress record for key when it was last updated before
+	// staleBefore, reporting whether it did. It runs in its own transaction.
+	Reclaim(ctx context.Context, key string, fingerprint string, staleBefore time.Time) (bool, error)
+	// Complete stores the serialized response and marks the record as completed
+	Complete(ctx context.Context, key string, response []byte) error
+	// Release removes the record in its own transaction so the request can be retried
+	Release(ctx context.Context, key string) error
+}
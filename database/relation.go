@@ -0,0 +1,113 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const (
+	RelationBelongsTo  = "belongs_to"
+	RelationHasOne     = "has_one"
+	RelationHasMany    = "has_many"
+	RelationManyToMany = "many_to_many"
+)
+
+// Relation is a relation declared on an entity field with the tags:
+//
+//	relation:           belongs_to, has_one, has_many or many_to_many
+//	table:              the table of the related entity
+//	foreign_key:        belongs_to: the column of the entity referencing the related entity,
+//	                    has_one and has_many: the column of the related entity referencing the entity
+//	references:         the referenced column of the entity, or of the related entity for
+//	                    belongs_to, defaults to id
+//	join_table:         many_to_many: the table linking the entities
+//	join_foreign_key:   many_to_many: the column of join_table referencing the entity
+//	join_references:    many_to_many: the column of join_table referencing the related entity
+//	related_references: many_to_many: the column of the related entity referenced by
+//	                    join_references, defaults to id
+//
+// The field is a pointer to the related struct for belongs_to and has_one, a slice of them
+// for has_many and many_to_many. Tag it db:"-" so it is not mapped to a column:
+//
+//	type Order struct {
+//		ID         string       `db:"id"`
+//		CustomerID string       `db:"customer_id"`
+//		Customer   *Customer    `db:"-" relation:"belongs_to" table:"customers" foreign_key:"customer_id"`
+//		Items      []*OrderItem `db:"-" relation:"has_many" table:"order_items" foreign_key:"order_id"`
+//		Tags       []*Tag       `db:"-" relation:"many_to_many" table:"tags" join_table:"order_tags" join_foreign_key:"order_id" join_references:"tag_id"`
+//	}
+type Relation struct {
+	Name           string
+	Kind           string
+	Table          string
+	ForeignKey     string
+	References     string
+	JoinTable      string
+	JoinForeignKey string
+	JoinReferences string
+	// RelatedReferences is the column of the related entity a many to many joins on
+	RelatedReferences string
+	// Type is the related struct type
+	Type reflect.Type
+}
+
+// GetRelation returns the relation declared on the field name of the struct type t
+func GetRelation(t reflect.Type, name string) (*Relation, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	field, ok := t.FieldByName(name)
+	if !ok || field.Tag.Get("relation") == "" {
+		return nil, fmt.Errorf("relation %s not found on %s", name, t.Name())
+	}
+	rel := &Relation{
+		Name:              name,
+		Kind:              field.Tag.Get("relation"),
+		Table:             field.Tag.Get("table"),
+		ForeignKey:        field.Tag.Get("foreign_key"),
+		References:        field.Tag.Get("references"),
+		JoinTable:         field.Tag.Get("join_table"),
+		JoinForeignKey:    field.Tag.Get("join_foreign_key"),
+		JoinReferences:    field.Tag.Get("join_references"),
+		RelatedReferences: field.Tag.Get("related_references"),
+	}
+	if rel.References == "" {
+		rel.References = "id"
+	}
+	if rel.RelatedReferences == "" {
+		rel.RelatedReferences = "id"
+	}
+
+	relType := field.Type
+	switch rel.Kind {
+	case RelationBelongsTo, RelationHasOne:
+		if relType.Kind() != reflect.Ptr {
+			return nil, fmt.Errorf("relation %s must be a pointer", name)
+		}
+	case RelationHasMany, RelationManyToMany:
+		if relType.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("relation %s must be a slice", name)
+		}
+		relType = relType.Elem()
+		if relType.Kind() == reflect.Ptr {
+			relType = relType.Elem()
+		}
+	default:
+		return nil, fmt.Errorf("unsupported relation %s: %s", name, rel.Kind)
+	}
+	if relType.Kind() == reflect.Ptr {
+		relType = relType.Elem()
+	}
+	if relType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("relation %s must reference a struct", name)
+	}
+	rel.Type = relType
+
+	if rel.Table == "" || (rel.ForeignKey == "" && rel.Kind != RelationManyToMany) {
+		return nil, fmt.Errorf("relation %s requires table and foreign_key", name)
+	}
+	if rel.Kind == RelationManyToMany && (rel.JoinTable == "" || rel.JoinForeignKey == "" || rel.JoinReferences == "") {
+		return nil, fmt.Errorf("relation %s requires join_table, join_foreign_key and join_references", name)
+	}
+	return rel, nil
+}
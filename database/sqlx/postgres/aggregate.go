@@ -0,0 +1,196 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+)
+
+// ErrUnsupportedAggregateCondition is returned by Aggregate when the condition has fields or
+// preloads, the selected columns being the group by fields and the aggregates
+var ErrUnsupportedAggregateCondition = errors.New("aggregate condition does not support fields nor preloads")
+
+// Aggregate groups the rows of repo matching condition and scans the groups into R, whose
+// fields are mapped on the grouped columns and the aggregate aliases:
+//
+//	type StatusTotal struct {
+//		Status string  `db:"status"`
+//		Count  int64   `db:"count"`
+//		Total  float64 `db:"total"`
+//	}
+//	condition := database.NewAggregateCondition().
+//		WithGroupBy("status").
+//		Count("*", "").
+//		Sum("amount", "total").
+//		WithHaving("total", 100, constants.GreaterThan)
+//	totals, err := Aggregate[Order, StatusTotal](ctx, repo, condition)
+func Aggregate[T any, R any](ctx context.Context, repo database.BaseRepository[T], condition *database.AggregateCondition) (_ []*R, err error) {
+	base, ok := repo.(interface{ base() *repository[T] })
+	if !ok {
+		return nil, ErrUnsupportedRepository
+	}
+	r := base.base()
+	ctx, finish := r.instrument(ctx, "Aggregate")
+	defer finish(&err)
+	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if condition == nil {
+		condition = database.NewAggregateCondition()
+	}
+	if len(condition.Fields) > 0 || len(condition.Preloads) > 0 {
+		return nil, ErrUnsupportedAggregateCondition
+	}
+
+	scoped, err := r.scopeCondition(ctx, &condition.CommonCondition)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
+		return nil, err
+	}
+	// the sorting may refer to the aliases, which must not be qualified
+	sorting := scoped.Sorting
+	scoped.Sorting = nil
+	scoped = qualifyCondition(table, scoped)
+	joined := len(scoped.Joins) > 0
+
+	expressions := make(map[string]string, len(condition.Aggregates))
+	columns := make([]string, 0, len(condition.GroupBy)+len(condition.Aggregates))
+	groupBy := make([]string, 0, len(condition.GroupBy))
+	for _, field := range condition.GroupBy {
+		if err = r.checkField(field); err != nil {
+			ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+			return nil, err
+		}
+		if joined {
+			field = qualifyColumn(table, field)
+		}
+		groupBy = append(groupBy, field)
+		columns = append(columns, field)
+	}
+	for _, aggregate := range condition.Aggregates {
+		expression, alias, err := r.getAggregate(aggregate, table, joined)
+		if err != nil {
+			ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+			return nil, err
+		}
+		expressions[alias] = expression
+		columns = append(columns, fmt.Sprintf("%s AS %s", expression, QuoteIdentifier(alias)))
+	}
+	for _, sort := range sorting {
+		if _, ok := expressions[sort.Field]; ok {
+			sort.Field = QuoteIdentifier(sort.Field)
+		} else {
+			if err = r.checkField(sort.Field); err != nil {
+				ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+				return nil, err
+			}
+			if joined {
+				sort.Field = qualifyColumn(table, sort.Field)
+			}
+		}
+		scoped.Sorting = append(scoped.Sorting, sort)
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	db := psql.Select(columns...).
+		From(table)
+	db, err = r.buildJoins(ctx, db, scoped.Joins)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return nil, err
+	}
+	db, err = BuildQuery(db, scoped)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return nil, err
+	}
+	if len(groupBy) > 0 {
+		db = db.GroupBy(groupBy...)
+	}
+	for _, having := range condition.Having {
+		// Postgres does not accept the aliases in HAVING, they are replaced by their expression
+		if expression, ok := expressions[having.Field]; ok {
+			having.Field = expression
+		} else {
+			if err = r.checkField(having.Field); err != nil {
+				ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+				return nil, err
+			}
+			if joined {
+				having.Field = qualifyColumn(table, having.Field)
+			}
+		}
+		pred, err := BuildPredicate(having)
+		if err != nil {
+			ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+			return nil, err
+		}
+		db = db.Having(pred)
+	}
+	query, args, err := db.ToSql()
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return nil, err
+	}
+	var results []*R
+	err = Select(ctx, r.readDB(ctx), &results, query, args...)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while select", logger.TableKey, r.table, logger.ErrorKey, err)
+		return nil, err
+	}
+	return results, nil
+}
+
+// getAggregate returns the expression of aggregate and its alias
+func (r *repository[T]) getAggregate(aggregate database.Aggregate, table string, joined bool) (string, string, error) {
+	fn := strings.ToLower(aggregate.Func)
+	switch fn {
+	case constants.Count, constants.Sum, constants.Avg, constants.Min, constants.Max:
+	default:
+		return "", "", fmt.Errorf("unsupported aggregate: %s", aggregate.Func)
+	}
+	field := aggregate.Field
+	if field == "*" && fn != constants.Count {
+		return "", "", fmt.Errorf("unsupported aggregate: %s(*)", aggregate.Func)
+	}
+	alias := aggregate.Alias
+	if field != "*" {
+		if err := r.checkField(field); err != nil {
+			return "", "", err
+		}
+		if alias == "" {
+			_, column, _ := strings.Cut(field, ".")
+			if column == "" {
+				column = field
+			}
+			alias = fn + "_" + column
+		}
+		if joined {
+			field = qualifyColumn(table, field)
+		}
+	} else if alias == "" {
+		alias = fn
+	}
+	return fmt.Sprintf("%s(%s)", strings.ToUpper(fn), field), alias, nil
+}
+
+// qualifiedColumnRegexp matches the qualified columns "<table or alias>.<column>"
+var qualifiedColumnRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\.[A-Za-z_][A-Za-z0-9_]*$`)
+
+// checkField fails with ErrUnknownField when field is neither a column of the entity nor
+// a qualified column of a joined table, the fields being written in the statement as is
+func (r *repository[T]) checkField(field string) error {
+	if r.columns[field] || qualifiedColumnRegexp.MatchString(field) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownField, field)
+}
@@ -0,0 +1,162 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+type customerTotal struct {
+	CustomerID string `db:"customer_id"`
+	Count      int64  `db:"count"`
+}
+
+func TestGetAggregate(t *testing.T) {
+	db, _ := newMockDB(t)
+	repo := NewRepository[order](db, "orders").(*repository[order])
+	tests := []struct {
+		name       string
+		aggregate  database.Aggregate
+		joined     bool
+		expression string
+		alias      string
+		wantErr    error
+	}{
+		{
+			name:       "count all",
+			aggregate:  database.Aggregate{Func: constants.Count, Field: "*"},
+			expression: "COUNT(*)",
+			alias:      "count",
+		},
+		{
+			name:       "default alias",
+			aggregate:  database.Aggregate{Func: "MAX", Field: "customer_id"},
+			expression: "MAX(customer_id)",
+			alias:      "max_customer_id",
+		},
+		{
+			name:       "qualified when joined",
+			aggregate:  database.Aggregate{Func: constants.Min, Field: "id", Alias: "first"},
+			joined:     true,
+			expression: "MIN(orders.id)",
+			alias:      "first",
+		},
+		{
+			name:       "column of a joined table",
+			aggregate:  database.Aggregate{Func: constants.Count, Field: "c.id"},
+			joined:     true,
+			expression: "COUNT(c.id)",
+			alias:      "count_id",
+		},
+		{
+			name:      "unsupported function",
+			aggregate: database.Aggregate{Func: "pg_sleep", Field: "id"},
+			wantErr:   errors.New("unsupported aggregate: pg_sleep"),
+		},
+		{
+			name:      "sum of all",
+			aggregate: database.Aggregate{Func: constants.Sum, Field: "*"},
+			wantErr:   errors.New("unsupported aggregate: sum(*)"),
+		},
+		{
+			name:      "unknown field",
+			aggregate: database.Aggregate{Func: constants.Sum, Field: "amount) FROM users --"},
+			wantErr:   ErrUnknownField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, alias, err := repo.getAggregate(tt.aggregate, "orders", tt.joined)
+			if tt.wantErr != nil {
+				if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+					t.Fatalf("getAggregate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expression != tt.expression || alias != tt.alias {
+				t.Errorf("getAggregate() = %s, %s, want %s, %s", expression, alias, tt.expression, tt.alias)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRepository[order](db, "orders")
+	mock.ExpectQuery(`SELECT customer_id, COUNT(*) AS "count" FROM orders GROUP BY customer_id HAVING COUNT(*) > $1 ORDER BY "count" DESC, customer_id`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"customer_id", "count"}).AddRow("1", 2))
+	condition := database.NewAggregateCondition().
+		WithGroupBy("customer_id").
+		Count("*", "").
+		WithHaving("count", 1, constants.GreaterThan)
+	condition.WithSorting("count", "desc").WithSorting("customer_id", "asc")
+	totals, err := Aggregate[order, customerTotal](context.Background(), repo, condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 1 || totals[0].CustomerID != "1" || totals[0].Count != 2 {
+		t.Errorf("totals = %+v", totals)
+	}
+}
+
+func TestAggregateRejectsCondition(t *testing.T) {
+	db, _ := newMockDB(t)
+	repo := NewRepository[order](db, "orders")
+	tests := []struct {
+		name      string
+		condition func() *database.AggregateCondition
+		wantErr   error
+	}{
+		{
+			name: "fields",
+			condition: func() *database.AggregateCondition {
+				condition := database.NewAggregateCondition().Count("*", "")
+				condition.WithFields("id")
+				return condition
+			},
+			wantErr: ErrUnsupportedAggregateCondition,
+		},
+		{
+			name: "preloads",
+			condition: func() *database.AggregateCondition {
+				condition := database.NewAggregateCondition().Count("*", "")
+				condition.Preload("Customer")
+				return condition
+			},
+			wantErr: ErrUnsupportedAggregateCondition,
+		},
+		{
+			name: "unknown having field",
+			condition: func() *database.AggregateCondition {
+				return database.NewAggregateCondition().Count("*", "").
+					WithHaving("1=1 OR id", 1, constants.Equal)
+			},
+			wantErr: ErrUnknownField,
+		},
+		{
+			name: "unknown sort field",
+			condition: func() *database.AggregateCondition {
+				condition := database.NewAggregateCondition().Count("*", "")
+				condition.WithSorting("(SELECT 1)", "asc")
+				return condition
+			},
+			wantErr: ErrUnknownField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Aggregate[order, customerTotal](context.Background(), repo, tt.condition())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Aggregate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// auditStore keeps audit records in a Postgres table. The table is expected to look like:
+//
+//	CREATE TABLE audit_logs (
+//		id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+//		entity_table   TEXT NOT NULL,
+//		entity_id      TEXT NOT NULL,
+//		action         TEXT NOT NULL,
+//		actor_id       TEXT NOT NULL,
+//		trace_id       TEXT NOT NULL,
+//		before         JSONB,
+//		after          JSONB,
+//		changed_fields JSONB,
+//		created_at     TIMESTAMPTZ NOT NULL
+//	);
+//	CREATE INDEX ON audit_logs (entity_table, entity_id, created_at);
+type auditStore struct {
+	repository database.BaseRepository[database.AuditRecord]
+}
+
+// NewAuditStore stores the records in table. WithAudit is ignored in opts, the records
+// are not audited themselves.
+func NewAuditStore(db *sqlx.DB, table string, opts ...RepositoryOption) database.AuditStore {
+	repo := NewRepository[database.AuditRecord](db, table, opts...)
+	if audited, ok := repo.(*auditedRepository[database.AuditRecord]); ok {
+		repo = audited.repository
+	}
+	return &auditStore{
+		repository: repo,
+	}
+}
+
+func (s *auditStore) Record(ctx context.Context, records ...*database.AuditRecord) error {
+	_, err := s.repository.CreateMany(ctx, records)
+	return err
+}
+
+func (s *auditStore) GetHistory(ctx context.Context, table string, id string) ([]*database.AuditRecord, error) {
+	condition := database.NewCommonCondition().
+		WithCondition("entity_table", table, constants.Equal).
+		WithCondition("entity_id", id, constants.Equal).
+		WithSorting(CreatedAtColumn, constants.Asc)
+	return s.repository.GetMany(NewContextWithPrimary(ctx), condition)
+}
+
+// auditBatchSize is the number of rows loaded at once by the deletes matching many rows
+const auditBatchSize = 500
+
+// auditedRepository records the changes made through the repository in an AuditStore,
+// in the same transaction as the change. A transaction is started when ctx has none.
+type auditedRepository[T any] struct {
+	*repository[T]
+	store database.AuditStore
+}
+
+// entityKey is the projection of the entities on their id
+type entityKey struct {
+	ID string `db:"id"`
+}
+
+func (a *auditedRepository[T]) Create(ctx context.Context, entity *T) (result *T, err error) {
+	err = a.inTransaction(ctx, func(ctx context.Context) error {
+		result, err = a.repository.Create(ctx, entity)
+		if err != nil {
+			return err
+		}
+		return a.record(ctx, database.AuditActionCreate, result)
+	})
+	return result, err
+}
+
+func (a *auditedRepository[T]) CreateMany(ctx context.Context, entities []*T) (ids []string, err error) {
+	err = a.inTransaction(ctx, func(ctx context.Context) error {
+		ids, err = a.repository.CreateMany(ctx, entities)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		created, err := a.repository.GetByIds(ctx, ids)
+		if err != nil {
+			return err
+		}
+		return a.record(ctx, database.AuditActionCreate, created...)
+	})
+	return ids, err
+}
+
+func (a *auditedRepository[T]) Update(ctx context.Context, id string, entity *T) error {
+	return a.inTransaction(ctx, func(ctx context.Context) error {
+		before, err := a.repository.GetById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = a.repository.Update(ctx, id, entity); err != nil {
+			return err
+		}
+		after, err := a.repository.GetById(ctx, id)
+		if err != nil {
+			return err
+		}
+		return a.recordChange(ctx, database.AuditActionUpdate, id, before, after)
+	})
+}
+
+func (a *auditedRepository[T]) Delete(ctx context.Context, id string) error {
+	return a.deleteWith(ctx, database.AuditActionDelete, func(ctx context.Context) ([]*T, error) {
+		return a.repository.GetByIds(ctx, []string{id})
+	}, func(ctx context.Context) error {
+		return a.repository.Delete(ctx, id)
+	})
+}
+
+func (a *auditedRepository[T]) DeleteMany(ctx context.Context, ids []string) error {
+	return a.deleteWith(ctx, database.AuditActionDelete, func(ctx context.Context) ([]*T, error) {
+		return a.repository.GetByIds(ctx, ids)
+	}, func(ctx context.Context) error {
+		return a.repository.DeleteMany(ctx, ids)
+	})
+}
+
+// DeleteByCondition selects the ids of the matching rows, then deletes and records them by batch
+func (a *auditedRepository[T]) DeleteByCondition(ctx context.Context, condition *database.CommonCondition) error {
+	condition = getCondition(condition)
+	if len(condition.Conditions) == 0 {
+		return ErrEmptyCondition
+	}
+	if len(condition.Joins) > 0 {
+		return ErrJoinNotSupported
+	}
+	return a.inTransaction(ctx, func(ctx context.Context) error {
+		// every matching row is deleted, whatever the paging
+		ids, err := a.getIds(ctx, &database.CommonCondition{
+			Conditions:      condition.Conditions,
+			IsSkipDeletedAt: condition.IsSkipDeletedAt,
+		})
+		if err != nil {
+			return err
+		}
+		for _, batch := range chunks(ids, auditBatchSize) {
+			if err = a.DeleteMany(ctx, batch); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (a *auditedRepository[T]) HardDelete(ctx context.Context, id string) error {
+	return a.deleteWith(ctx, database.AuditActionDelete, func(ctx context.Context) ([]*T, error) {
+		if !a.softDelete {
+			return a.repository.GetByIds(ctx, []string{id})
+		}
+		// the row may already be soft deleted
+		return a.repository.GetMany(ctx, database.NewCommonCondition().
+			WithCondition("id", id, constants.Equal).
+			SkipDeletedAt())
+	}, func(ctx context.Context) error {
+		return a.repository.HardDelete(ctx, id)
+	})
+}
+
+// PurgeDeleted records the purged rows by batch before removing them
+func (a *auditedRepository[T]) PurgeDeleted(ctx context.Context, before time.Time) error {
+	if !a.softDelete {
+		return ErrSoftDeleteNotSupported
+	}
+	return a.inTransaction(ctx, func(ctx context.Context) error {
+		ids, err := a.getIds(ctx, database.NewCommonCondition().
+			WithCondition(DeletedAtColumn, before, constants.LessThan).
+			SkipDeletedAt())
+		if err != nil {
+			return err
+		}
+		for _, batch := range chunks(ids, auditBatchSize) {
+			err = a.deleteWith(ctx, database.AuditActionPurge, func(ctx context.Context) ([]*T, error) {
+				return a.repository.GetMany(ctx, database.NewCommonCondition().
+					WithCondition("id", batch, constants.In).
+					SkipDeletedAt())
+			}, func(ctx context.Context) error {
+				table, err := a.getTable(ctx)
+				if err != nil {
+					return err
+				}
+				return a.hardDeleteWhere(ctx, table, sq.Eq{"id": batch})
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (a *auditedRepository[T]) Restore(ctx context.Context, id string) error {
+	return a.inTransaction(ctx, func(ctx context.Context) error {
+		if err := a.repository.Restore(ctx, id); err != nil {
+			return err
+		}
+		after, err := a.repository.GetById(ctx, id)
+		if err != nil || after == nil {
+			return err
+		}
+		return a.record(ctx, database.AuditActionRestore, after)
+	})
+}
+
+// getIds returns the ids of the rows matching condition
+func (a *auditedRepository[T]) getIds(ctx context.Context, condition *database.CommonCondition) ([]string, error) {
+	table, err := a.getTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := selectMany[T, entityKey](ctx, a.repository, table, condition)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = key.ID
+	}
+	return ids, nil
+}
+
+// deleteWith records the rows returned by get with action once del succeeded
+func (a *auditedRepository[T]) deleteWith(ctx context.Context, action string, get func(ctx context.Context) ([]*T, error), del func(ctx context.Context) error) error {
+	return a.inTransaction(ctx, func(ctx context.Context) error {
+		before, err := get(ctx)
+		if err != nil {
+			return err
+		}
+		if err = del(ctx); err != nil {
+			return err
+		}
+		records := make([]*database.AuditRecord, 0, len(before))
+		for _, entity := range before {
+			record, err := a.newRecord(ctx, action, getEntityID(entity), entity, nil)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		return a.store.Record(ctx, records...)
+	})
+}
+
+// record stores an action which brought entities to their current state
+func (a *auditedRepository[T]) record(ctx context.Context, action string, entities ...*T) error {
+	records := make([]*database.AuditRecord, 0, len(entities))
+	for _, entity := range entities {
+		record, err := a.newRecord(ctx, action, getEntityID(entity), nil, entity)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return a.store.Record(ctx, records...)
+}
+
+func (a *auditedRepository[T]) recordChange(ctx context.Context, action string, id string, before *T, after *T) error {
+	if before == nil && after == nil {
+		return nil
+	}
+	record, err := a.newRecord(ctx, action, id, before, after)
+	if err != nil {
+		return err
+	}
+	return a.store.Record(ctx, record)
+}
+
+func (a *auditedRepository[T]) newRecord(ctx context.Context, action string, id string, before *T, after *T) (*database.AuditRecord, error) {
+	beforeJSON, err := marshalEntity(before)
+	if err != nil {
+		return nil, err
+	}
+	afterJSON, err := marshalEntity(after)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := getChangedFields(beforeJSON, afterJSON)
+	if err != nil {
+		return nil, err
+	}
+	changedJSON, err := json.Marshal(changed)
+	if err != nil {
+		return nil, err
+	}
+	traceID, _ := logger.GetTraceIDs(ctx)
+	return &database.AuditRecord{
+		EntityTable:   a.table,
+		EntityID:      id,
+		Action:        action,
+		ActorID:       database.GetActorID(ctx),
+		TraceID:       traceID,
+		Before:        beforeJSON,
+		After:         afterJSON,
+		ChangedFields: changedJSON,
+	}, nil
+}
+
+// inTransaction runs fn in the context transaction, starting one when there is none
+func (a *auditedRepository[T]) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if GetContextTransaction(ctx) != nil {
+		return fn(ctx)
+	}
+	ctxLogger := logger.NewLogger(ctx)
+	tm := database.NewTransactionManager(a.db)
+	ctx, err := tm.BeginTransaction(ctx)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while begin transaction", logger.ErrorKey, err)
+		return err
+	}
+	if err = fn(ctx); err != nil {
+		if rbErr := tm.RollbackTransaction(ctx); rbErr != nil {
+			ctxLogger.Errorw(logger.MsgKey, "Failed to rollback transaction", logger.ErrorKey, rbErr)
+		}
+		return err
+	}
+	return tm.CommitTransaction(ctx)
+}
+
+func marshalEntity[T any](entity *T) ([]byte, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	return json.Marshal(entity)
+}
+
+// getChangedFields returns the sorted keys of the JSON objects before and after whose values differ
+func getChangedFields(before, after []byte) ([]string, error) {
+	var beforeFields, afterFields map[string]interface{}
+	if before != nil {
+		if err := json.Unmarshal(before, &beforeFields); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		if err := json.Unmarshal(after, &afterFields); err != nil {
+			return nil, err
+		}
+	}
+	changed := make([]string, 0)
+	for key, value := range afterFields {
+		if old, ok := beforeFields[key]; !ok || !reflect.DeepEqual(old, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range beforeFields {
+		if _, ok := afterFields[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// getEntityID returns the value of the id column of entity
+func getEntityID[T any](entity *T) string {
+	if entity == nil {
+		return ""
+	}
+	columns, values, err := database.GetColumnsAndValues(entity)
+	if err != nil {
+		return ""
+	}
+	for i, column := range columns {
+		if column == "id" {
+			return fmt.Sprint(values[i])
+		}
+	}
+	return ""
+}
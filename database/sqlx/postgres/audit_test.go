@@ -0,0 +1,180 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+const insertAuditQuery = `INSERT INTO audit_logs (entity_table,entity_id,action,actor_id,trace_id,before,after,changed_fields,created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id`
+
+// expectUpdate expects the statements of the audited Update of item 1 from a to b
+func expectUpdate(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`SELECT id, name FROM items WHERE id = $1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "a"))
+	mock.ExpectExec(`UPDATE items SET name = $1 WHERE id = $2`).
+		WithArgs("b", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id, name FROM items WHERE id = $1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "b"))
+}
+
+func TestAuditInTransactionOfChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		auditErr error
+		wantErr  bool
+	}{
+		{name: "audit recorded, change committed"},
+		{name: "audit failed, change rolled back", auditErr: errors.New("audit failed"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			repo := NewRepository[item](db, "items", WithAudit(NewAuditStore(db, "audit_logs")))
+			mock.ExpectBegin()
+			expectUpdate(mock)
+			insert := mock.ExpectQuery(insertAuditQuery).
+				WithArgs("items", "1", database.AuditActionUpdate, "", "", sqlmock.AnyArg(), sqlmock.AnyArg(), []byte(`["name"]`), sqlmock.AnyArg())
+			if tt.auditErr != nil {
+				insert.WillReturnError(tt.auditErr)
+				mock.ExpectRollback()
+			} else {
+				insert.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1"))
+				mock.ExpectCommit()
+			}
+			err := repo.Update(context.Background(), "1", &item{ID: "1", Name: "b"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuditRollsBackWithCallerTransaction(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRepository[item](db, "items", WithAudit(NewAuditStore(db, "audit_logs")))
+	mock.ExpectBegin()
+	expectUpdate(mock)
+	mock.ExpectQuery(insertAuditQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1"))
+	// the repository neither commits nor rolls back the transaction of the caller,
+	// so the audit record goes away with the change
+	mock.ExpectRollback()
+
+	tm := database.NewTransactionManager(db)
+	ctx, err := tm.BeginTransaction(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.Update(ctx, "1", &item{ID: "1", Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = tm.RollbackTransaction(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditedDeleteByCondition(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRepository[softItem](db, "items", WithAudit(NewAuditStore(db, "audit_logs")))
+	if err := repo.DeleteByCondition(context.Background(), nil); !errors.Is(err, ErrEmptyCondition) {
+		t.Fatalf("DeleteByCondition(nil) = %v, want ErrEmptyCondition", err)
+	}
+
+	mock.ExpectBegin()
+	// only the ids are selected, the rows are then loaded by batch
+	mock.ExpectQuery(`SELECT id FROM items WHERE name = $1 AND deleted_at IS NULL`).
+		WithArgs("a").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	mock.ExpectQuery(`SELECT id, name, deleted_at FROM items WHERE id IN ($1) AND deleted_at IS NULL`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at"}).AddRow("1", "a", nil))
+	mock.ExpectExec(`UPDATE items SET deleted_at = $1 WHERE id IN ($2) AND deleted_at IS NULL`).
+		WithArgs(sqlmock.AnyArg(), "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(insertAuditQuery).
+		WithArgs("items", "1", database.AuditActionDelete, "", "", sqlmock.AnyArg(), []byte(nil), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1"))
+	mock.ExpectCommit()
+	condition := database.NewCommonCondition().WithCondition("name", "a", constants.Equal)
+	if err := repo.DeleteByCondition(context.Background(), condition); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditedPurgeDeleted(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRepository[softItem](db, "items", WithAudit(NewAuditStore(db, "audit_logs")))
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deletedAt := before.Add(-time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM items WHERE deleted_at < $1`).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	mock.ExpectQuery(`SELECT id, name, deleted_at FROM items WHERE id IN ($1)`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at"}).AddRow("1", "a", deletedAt))
+	mock.ExpectExec(`DELETE FROM items WHERE id IN ($1)`).
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(insertAuditQuery).
+		WithArgs("items", "1", database.AuditActionPurge, "", "", sqlmock.AnyArg(), []byte(nil), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1"))
+	mock.ExpectCommit()
+	if err := repo.PurgeDeleted(context.Background(), before); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewAuditStoreIgnoresWithAudit(t *testing.T) {
+	db, _ := newMockDB(t)
+	opts := []RepositoryOption{WithAudit(NewAuditStore(db, "audit_logs"))}
+	store := NewAuditStore(db, "audit_logs", opts...).(*auditStore)
+	if _, ok := store.repository.(*auditedRepository[database.AuditRecord]); ok {
+		t.Fatal("the audit store audits its own records")
+	}
+}
+
+func TestGetChangedFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []string
+	}{
+		{name: "create", after: `{"id":"1","name":"a"}`, want: []string{"id", "name"}},
+		{name: "delete", before: `{"id":"1","name":"a"}`, want: []string{"id", "name"}},
+		{name: "update", before: `{"id":"1","name":"a","age":1}`, after: `{"id":"1","name":"b","age":1}`, want: []string{"name"}},
+		{name: "unchanged", before: `{"id":"1","tags":["a"]}`, after: `{"id":"1","tags":["a"]}`, want: []string{}},
+		{name: "nested", before: `{"meta":{"a":1}}`, after: `{"meta":{"a":2}}`, want: []string{"meta"}},
+		{name: "added and removed", before: `{"a":1}`, after: `{"b":1}`, want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after []byte
+			if tt.before != "" {
+				before = []byte(tt.before)
+			}
+			if tt.after != "" {
+				after = []byte(tt.after)
+			}
+			got, err := getChangedFields(before, after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getChangedFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
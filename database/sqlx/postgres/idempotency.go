@@ -0,0 +1,147 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// idempotencyStore keeps idempotency records in a Postgres table. The key is claimed
+// and released on the pool, each in its own short transaction, so a concurrent request
+// sees the claim at once instead of waiting on the use case transaction. The response is
+// stored by Complete in the context transaction, so it is committed along with the use
+// case changes. The keys are unique per table, callers are kept apart by the scope the
+// middleware adds to them; with WithTenantColumn, the unique constraint must include the tenant
+// column, as in UNIQUE (tenant_id, key). The table is expected to look like:
+//
+//	CREATE TABLE idempotency_keys (
+//		id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+//		key         TEXT NOT NULL UNIQUE,
+//		fingerprint TEXT NOT NULL,
+//		status      TEXT NOT NULL,
+//		response    JSONB,
+//		created_at  TIMESTAMPTZ NOT NULL,
+//		updated_at  TIMESTAMPTZ NOT NULL,
+//		deleted_at  TIMESTAMPTZ
+//	);
+type idempotencyStore struct {
+	db         *sqlx.DB
+	table      string
+	options    repositoryOptions
+	repository database.BaseRepository[database.IdempotencyRecord]
+}
+
+func NewIdempotencyStore(db *sqlx.DB, table string, opts ...RepositoryOption) database.IdempotencyStore {
+	options := getRepositoryOptions(opts)
+	return &idempotencyStore{
+		db:         options.getDB(db),
+		table:      table,
+		options:    options,
+		repository: NewRepository[database.IdempotencyRecord](db, table, opts...),
+	}
+}
+
+func (s *idempotencyStore) Get(ctx context.Context, key string) (*database.IdempotencyRecord, error) {
+	condition := database.NewCommonCondition().WithCondition("key", key, constants.Equal)
+	// a replica may not have the record acquired by a concurrent request yet
+	records, err := s.repository.GetMany(NewContextWithPrimary(withoutTransaction(ctx)), condition)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return records[0], nil
+}
+
+func (s *idempotencyStore) Acquire(ctx context.Context, key string, fingerprint string) (*database.IdempotencyRecord, error) {
+	now := time.Now()
+	record, err := s.repository.Create(withoutTransaction(ctx), &database.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      database.IdempotencyStatusInProgress,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		if IsUniqueViolation(err) {
+			return nil, database.ErrIdempotencyKeyExists
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+func (s *idempotencyStore) Reclaim(ctx context.Context, key string, fingerprint string, staleBefore time.Time) (bool, error) {
+	ctx = withQueryScope(ctx, &queryScope{
+		table:        s.table,
+		method:       "Reclaim",
+		interceptors: s.options.interceptors,
+	})
+	ctxLogger := logger.NewLogger(ctx)
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query, args, err := psql.Update(s.table).
+		Set("fingerprint", fingerprint).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"key": key, "status": database.IdempotencyStatusInProgress}).
+		Where(sq.Lt{"updated_at": staleBefore}).
+		ToSql()
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return false, err
+	}
+	var rows int64
+	ctx = withoutTransaction(ctx)
+	err = runQuery(ctx, s.db, OperationUpdate, query, args, func(ctx context.Context, query string, args []interface{}) (int64, error) {
+		rows, err = execContext(ctx, s.db, query, args)
+		return rows, err
+	})
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while update", logger.TableKey, s.table, logger.ErrorKey, err)
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (s *idempotencyStore) Complete(ctx context.Context, key string, response []byte) error {
+	record, err := s.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return errors.New("idempotency record not found")
+	}
+	record.Status = database.IdempotencyStatusCompleted
+	record.Response = response
+	record.UpdatedAt = time.Now()
+	return s.repository.Update(ctx, record.ID, record)
+}
+
+func (s *idempotencyStore) Release(ctx context.Context, key string) error {
+	ctx = withQueryScope(ctx, &queryScope{
+		table:        s.table,
+		method:       "Release",
+		interceptors: s.options.interceptors,
+	})
+	ctxLogger := logger.NewLogger(ctx)
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query, args, err := psql.Delete(s.table).
+		Where(sq.Eq{"key": key}).
+		ToSql()
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return err
+	}
+	err = Delete(withoutTransaction(ctx), s.db, query, args...)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while delete", logger.TableKey, s.table, logger.ErrorKey, err)
+		return err
+	}
+	return nil
+}
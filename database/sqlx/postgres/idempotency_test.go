@@ -0,0 +1,144 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "pq: " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+var idempotencyColumns = []string{"id", "key", "fingerprint", "status", "response", "created_at", "updated_at", "deleted_at"}
+
+const selectIdempotencyQuery = `SELECT id, key, fingerprint, status, response, created_at, updated_at, deleted_at FROM idempotency_keys WHERE key = $1 AND deleted_at IS NULL`
+
+func TestIdempotencyStoreGet(t *testing.T) {
+	db, mock := newMockDB(t)
+	store := NewIdempotencyStore(db, "idempotency_keys")
+	now := time.Now()
+	mock.ExpectQuery(selectIdempotencyQuery).
+		WithArgs("k").
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns).
+			AddRow("1", "k", "f", database.IdempotencyStatusCompleted, []byte(`{}`), now, now, nil))
+	mock.ExpectQuery(selectIdempotencyQuery).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns))
+
+	record, err := store.Get(context.Background(), "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil || record.ID != "1" || record.Status != database.IdempotencyStatusCompleted {
+		t.Errorf("Get() = %+v", record)
+	}
+	if record, err = store.Get(context.Background(), "missing"); err != nil || record != nil {
+		t.Errorf("Get(missing) = %+v, %v", record, err)
+	}
+}
+
+func TestIdempotencyStoreAcquire(t *testing.T) {
+	const insertQuery = `INSERT INTO idempotency_keys (key,fingerprint,status,response,created_at,updated_at,deleted_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id`
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "acquired"},
+		{name: "key exists", err: sqlStateError(uniqueViolationCode), wantErr: database.ErrIdempotencyKeyExists},
+		{name: "other error", err: errors.New("connection reset"), wantErr: errors.New("connection reset")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			store := NewIdempotencyStore(db, "idempotency_keys")
+			expect := mock.ExpectQuery(insertQuery).
+				WithArgs("k", "f", database.IdempotencyStatusInProgress, []byte(nil), sqlmock.AnyArg(), sqlmock.AnyArg(), nil)
+			if tt.err != nil {
+				expect.WillReturnError(tt.err)
+			} else {
+				now := time.Now()
+				expect.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+				mock.ExpectQuery(`SELECT count(*) FROM idempotency_keys WHERE id = $1 AND deleted_at IS NULL`).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT id, key, fingerprint, status, response, created_at, updated_at, deleted_at FROM idempotency_keys WHERE id = $1 AND deleted_at IS NULL`).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(idempotencyColumns).
+						AddRow("1", "k", "f", database.IdempotencyStatusInProgress, nil, now, now, nil))
+			}
+			_, err := store.Acquire(context.Background(), "k", "f")
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("Acquire() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIdempotencyStoreReclaim(t *testing.T) {
+	const updateQuery = `UPDATE idempotency_keys SET fingerprint = $1, updated_at = $2 WHERE key = $3 AND status = $4 AND updated_at < $5`
+	tests := []struct {
+		name string
+		rows int64
+		want bool
+	}{
+		{name: "stale record", rows: 1, want: true},
+		{name: "record still held", rows: 0, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			store := NewIdempotencyStore(db, "idempotency_keys")
+			staleBefore := time.Now().Add(-time.Minute)
+			mock.ExpectExec(updateQuery).
+				WithArgs("f", sqlmock.AnyArg(), "k", database.IdempotencyStatusInProgress, staleBefore).
+				WillReturnResult(sqlmock.NewResult(0, tt.rows))
+			got, err := store.Reclaim(context.Background(), "k", "f", staleBefore)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Reclaim() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdempotencyStoreComplete(t *testing.T) {
+	db, mock := newMockDB(t)
+	store := NewIdempotencyStore(db, "idempotency_keys")
+	now := time.Now()
+	mock.ExpectQuery(selectIdempotencyQuery).
+		WithArgs("k").
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns).
+			AddRow("1", "k", "f", database.IdempotencyStatusInProgress, nil, now, now, nil))
+	mock.ExpectExec(`UPDATE idempotency_keys SET key = $1, fingerprint = $2, status = $3, response = $4, updated_at = $5 WHERE id = $6`).
+		WithArgs("k", "f", database.IdempotencyStatusCompleted, []byte(`{"ok":true}`), sqlmock.AnyArg(), "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := store.Complete(context.Background(), "k", []byte(`{"ok":true}`)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIdempotencyStoreRelease(t *testing.T) {
+	db, mock := newMockDB(t)
+	store := NewIdempotencyStore(db, "idempotency_keys")
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE key = $1`).
+		WithArgs("k").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := store.Release(context.Background(), "k"); err != nil {
+		t.Fatal(err)
+	}
+}
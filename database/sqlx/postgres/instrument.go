@@ -0,0 +1,79 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/dotrongnhan/sharing-package/pkg/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/dotrongnhan/sharing-package/database/sqlx/postgres"
+
+	DBSystemKey       = attribute.Key("db.system")
+	DBTableKey        = attribute.Key("db.table")
+	DBOperationKey    = attribute.Key("db.operation")
+	DBStatementKey    = attribute.Key("db.statement")
+	DBRowsAffectedKey = attribute.Key("db.rows_affected")
+)
+
+// instrument starts the span of a repository operation and scopes the statements of ctx
+// to it, the returned function ends the span and records the metrics. It is meant to be
+// deferred with the named error result.
+func (r *repository[T]) instrument(ctx context.Context, method string) (context.Context, func(*error)) {
+	start := time.Now()
+	ctx = withQueryScope(ctx, &queryScope{
+		table:        r.table,
+		method:       method,
+		interceptors: r.options.interceptors,
+	})
+	ctx, span := otel.Tracer(tracerName).Start(ctx, r.table+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			DBSystemKey.String("postgresql"),
+			DBTableKey.String(r.table),
+			DBOperationKey.String(method),
+		),
+	)
+	return ctx, func(err *error) {
+		metrics.RepositoryQueries.WithLabelValues(r.table, method).Inc()
+		metrics.RepositoryDuration.WithLabelValues(r.table, method).Observe(time.Since(start).Seconds())
+		if *err != nil {
+			metrics.RepositoryErrors.WithLabelValues(r.table, method, metrics.ErrorClass(*err)).Inc()
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
+
+// traceInterceptor adds the executed statement and the rows it affected to the current span
+type traceInterceptor struct{}
+
+func (traceInterceptor) BeforeQuery(ctx context.Context, _ *Query) (context.Context, error) {
+	return ctx, nil
+}
+
+func (traceInterceptor) AfterQuery(ctx context.Context, query *Query, result *QueryResult) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() || result.Err != nil {
+		return
+	}
+	span.SetAttributes(DBStatementKey.String(query.Statement), DBRowsAffectedKey.Int64(result.Rows))
+}
+
+func countRows(dest interface{}) int64 {
+	v := reflect.ValueOf(dest)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice {
+		return int64(v.Len())
+	}
+	return 1
+}
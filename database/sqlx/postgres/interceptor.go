@@ -0,0 +1,150 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	OperationSelect = "select"
+	OperationInsert = "insert"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Query is a statement about to be run by Select, Insert, InsertMultiple, Exec or Delete.
+// Interceptors may rewrite Statement and Args in BeforeQuery.
+type Query struct {
+	// DB is the connection the statement runs on, when a transaction is in the context
+	// the statement runs in it instead
+	DB *sqlx.DB
+	// Table and Method are the repository table and method, empty when a helper is
+	// called outside of a repository
+	Table     string
+	Method    string
+	Operation string
+	Statement string
+	Args      []interface{}
+}
+
+type QueryResult struct {
+	// Rows is the number of rows returned or affected
+	Rows     int64
+	Duration time.Duration
+	Err      error
+}
+
+type QueryInterceptor interface {
+	// BeforeQuery runs before the statement, a non nil error aborts it. The returned
+	// context is passed to the statement and to AfterQuery.
+	BeforeQuery(ctx context.Context, query *Query) (context.Context, error)
+	// AfterQuery runs once the statement returned, or when a later BeforeQuery failed
+	AfterQuery(ctx context.Context, query *Query, result *QueryResult)
+}
+
+// QueryInterceptorFuncs adapts a pair of functions to QueryInterceptor, nil functions are skipped
+type QueryInterceptorFuncs struct {
+	Before func(ctx context.Context, query *Query) (context.Context, error)
+	After  func(ctx context.Context, query *Query, result *QueryResult)
+}
+
+func (f QueryInterceptorFuncs) BeforeQuery(ctx context.Context, query *Query) (context.Context, error) {
+	if f.Before == nil {
+		return ctx, nil
+	}
+	return f.Before(ctx, query)
+}
+
+func (f QueryInterceptorFuncs) AfterQuery(ctx context.Context, query *Query, result *QueryResult) {
+	if f.After != nil {
+		f.After(ctx, query, result)
+	}
+}
+
+var (
+	globalInterceptorsMu sync.RWMutex
+	globalInterceptors   []QueryInterceptor
+)
+
+// RegisterQueryInterceptor adds interceptors run for every statement, before the ones
+// of the repository. It is meant to be called at startup.
+func RegisterQueryInterceptor(interceptors ...QueryInterceptor) {
+	globalInterceptorsMu.Lock()
+	defer globalInterceptorsMu.Unlock()
+	globalInterceptors = append(globalInterceptors[:len(globalInterceptors):len(globalInterceptors)], interceptors...)
+}
+
+func getGlobalInterceptors() []QueryInterceptor {
+	globalInterceptorsMu.RLock()
+	defer globalInterceptorsMu.RUnlock()
+	return globalInterceptors
+}
+
+// queryScope is the repository operation the statements of a context belong to
+type queryScope struct {
+	table        string
+	method       string
+	interceptors []QueryInterceptor
+}
+
+func withQueryScope(ctx context.Context, scope *queryScope) context.Context {
+	return context.WithValue(ctx, constants.ContextKeyQueryScope, scope)
+}
+
+func getQueryScope(ctx context.Context) *queryScope {
+	scope, _ := ctx.Value(constants.ContextKeyQueryScope).(*queryScope)
+	return scope
+}
+
+// builtinInterceptors run last, so they see the statement as rewritten by the others
+var builtinInterceptors = []QueryInterceptor{
+	traceInterceptor{},
+	queryLogInterceptor{},
+}
+
+// runQuery runs exec through the global, repository and built-in interceptors.
+// BeforeQuery is called in order and AfterQuery in reverse order, each with the
+// context its BeforeQuery returned.
+func runQuery(ctx context.Context, db *sqlx.DB, operation string, statement string, args []interface{},
+	exec func(ctx context.Context, statement string, args []interface{}) (int64, error)) error {
+	query := &Query{
+		DB:        db,
+		Operation: operation,
+		Statement: statement,
+		Args:      args,
+	}
+	global := getGlobalInterceptors()
+	interceptors := make([]QueryInterceptor, 0, len(global)+len(builtinInterceptors)+2)
+	interceptors = append(interceptors, global...)
+	if scope := getQueryScope(ctx); scope != nil {
+		query.Table = scope.table
+		query.Method = scope.method
+		interceptors = append(interceptors, scope.interceptors...)
+	}
+	interceptors = append(interceptors, builtinInterceptors...)
+
+	result := &QueryResult{}
+	contexts := make([]context.Context, 0, len(interceptors))
+	for _, interceptor := range interceptors {
+		nextCtx, err := interceptor.BeforeQuery(ctx, query)
+		if err != nil {
+			result.Err = err
+			break
+		}
+		ctx = nextCtx
+		contexts = append(contexts, ctx)
+	}
+	if result.Err == nil {
+		start := time.Now()
+		result.Rows, result.Err = exec(ctx, query.Statement, query.Args)
+		result.Duration = time.Since(start)
+	}
+	for i := len(contexts) - 1; i >= 0; i-- {
+		interceptors[i].AfterQuery(contexts[i], query, result)
+	}
+	return result.Err
+}
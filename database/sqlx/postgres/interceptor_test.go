@@ -0,0 +1,117 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type interceptorKey struct{}
+
+// recordInterceptor appends its calls to events, err fails its BeforeQuery
+func recordInterceptor(name string, events *[]string, err error) QueryInterceptor {
+	return QueryInterceptorFuncs{
+		Before: func(ctx context.Context, query *Query) (context.Context, error) {
+			*events = append(*events, "before "+name)
+			if err != nil {
+				return ctx, err
+			}
+			return context.WithValue(ctx, interceptorKey{}, name), nil
+		},
+		After: func(ctx context.Context, query *Query, result *QueryResult) {
+			event := "after " + name
+			if ctx.Value(interceptorKey{}) != name {
+				event += " without its context"
+			}
+			if result.Err != nil {
+				event += " failed"
+			}
+			*events = append(*events, event)
+		},
+	}
+}
+
+func TestInterceptorChain(t *testing.T) {
+	errBefore := errors.New("before failed")
+	tests := []struct {
+		name       string
+		global     bool
+		failing    string
+		wantEvents []string
+		wantErr    error
+	}{
+		{
+			name:       "runs BeforeQuery in order and AfterQuery in reverse order",
+			wantEvents: []string{"before a", "before b", "after b", "after a"},
+		},
+		{
+			name:       "runs the global interceptors first",
+			global:     true,
+			wantEvents: []string{"before global", "before a", "before b", "after b", "after a", "after global"},
+		},
+		{
+			name:       "stops at the failing BeforeQuery",
+			failing:    "b",
+			wantEvents: []string{"before a", "before b", "after a failed"},
+			wantErr:    errBefore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []string
+			if tt.global {
+				saved := globalInterceptors
+				t.Cleanup(func() { globalInterceptors = saved })
+				RegisterQueryInterceptor(recordInterceptor("global", &events, nil))
+			}
+			interceptors := make([]QueryInterceptor, 0, 2)
+			for _, name := range []string{"a", "b"} {
+				var err error
+				if name == tt.failing {
+					err = errBefore
+				}
+				interceptors = append(interceptors, recordInterceptor(name, &events, err))
+			}
+			db, mock := newMockDB(t)
+			repo := NewRepository[item](db, "items", WithInterceptors(interceptors...))
+			if tt.wantErr == nil {
+				mock.ExpectQuery(`SELECT id, name FROM items WHERE id = $1`).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "a"))
+			}
+			if _, err := repo.GetById(context.Background(), "1"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := strings.Join(events, ", "); got != strings.Join(tt.wantEvents, ", ") {
+				t.Errorf("events = %s, want %s", got, strings.Join(tt.wantEvents, ", "))
+			}
+		})
+	}
+}
+
+func TestInterceptorRewritesQuery(t *testing.T) {
+	db, mock := newMockDB(t)
+	var after *Query
+	repo := NewRepository[item](db, "items", WithInterceptors(QueryInterceptorFuncs{
+		Before: func(ctx context.Context, query *Query) (context.Context, error) {
+			query.Statement += " AND name = $2"
+			query.Args = append(query.Args, "a")
+			return ctx, nil
+		},
+		After: func(ctx context.Context, query *Query, result *QueryResult) {
+			after = query
+		},
+	}))
+	mock.ExpectQuery(`SELECT id, name FROM items WHERE id = $1 AND name = $2`).
+		WithArgs("1", "a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "a"))
+	if _, err := repo.GetById(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	if after == nil || after.Table != "items" || after.Method != "GetById" || after.Operation != OperationSelect {
+		t.Errorf("AfterQuery got %+v", after)
+	}
+}
@@ -0,0 +1,130 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+var (
+	// joinTableRegexp matches the joined tables "<table>" or "<schema>.<table>"
+	joinTableRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+	// joinAliasRegexp matches the aliases of the joined tables
+	joinAliasRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// buildJoins adds the joins of condition to db, the joined tables are resolved like the
+// repository table. The table, alias and On clause are written in the statement as is, the
+// table and alias must be plain identifiers, the On clause is not checked. The joined tables
+// are not filtered by the tenant column.
+func (r *repository[T]) buildJoins(ctx context.Context, db sq.SelectBuilder, joins []database.Join) (sq.SelectBuilder, error) {
+	for _, join := range joins {
+		if !joinTableRegexp.MatchString(join.Table) {
+			return db, fmt.Errorf("invalid join table: %s", join.Table)
+		}
+		if join.Alias != "" && !joinAliasRegexp.MatchString(join.Alias) {
+			return db, fmt.Errorf("invalid join alias: %s", join.Alias)
+		}
+		table, err := r.resolveTable(ctx, join.Table)
+		if err != nil {
+			return db, err
+		}
+		if join.Alias != "" {
+			table = fmt.Sprintf("%s AS %s", table, join.Alias)
+		}
+		clause := fmt.Sprintf("%s ON %s", table, join.On)
+		switch strings.ToLower(join.Type) {
+		case constants.InnerJoin, "":
+			db = db.InnerJoin(clause)
+		case constants.LeftJoin:
+			db = db.LeftJoin(clause)
+		default:
+			return db, fmt.Errorf("unsupported join type: %s", join.Type)
+		}
+	}
+	return db, nil
+}
+
+// qualifyCondition returns a copy of condition whose unqualified fields refer to table, so
+// they are not ambiguous with the columns of the joined tables
+func qualifyCondition(table string, condition *database.CommonCondition) *database.CommonCondition {
+	if len(condition.Joins) == 0 {
+		return condition
+	}
+	qualified := *condition
+	qualified.Conditions = make([]database.Condition, 0, len(condition.Conditions)+1)
+	for _, cond := range condition.Conditions {
+		cond.Field = qualifyColumn(table, cond.Field)
+		qualified.Conditions = append(qualified.Conditions, cond)
+	}
+	if !condition.IsSkipDeletedAt {
+		qualified.IsSkipDeletedAt = true
+		qualified.Conditions = append(qualified.Conditions, database.Condition{
+			Field: qualifyColumn(table, DeletedAtColumn),
+			Value: nil,
+			Op:    constants.Equal,
+		})
+	}
+	qualified.Sorting = make([]database.Sorting, len(condition.Sorting))
+	for i, sort := range condition.Sorting {
+		sort.Field = qualifyColumn(table, sort.Field)
+		qualified.Sorting[i] = sort
+	}
+	return &qualified
+}
+
+func qualifyColumn(table, column string) string {
+	if strings.Contains(column, ".") {
+		return column
+	}
+	return table + "." + column
+}
+
+// getJoinColumns returns the columns of the fields of t tagged join:"<table or alias>" of a
+// joined table, selected as "<field>.<column>" so sqlx maps them into the field. The fields
+// filled by a left join must be pointers or nullable types since the columns are NULL when
+// nothing matches.
+func (r *repository[T]) getJoinColumns(t reflect.Type, joins []database.Join) ([]string, error) {
+	joined := make(map[string]bool, len(joins))
+	for _, join := range joins {
+		if join.Alias != "" {
+			joined[join.Alias] = true
+		} else {
+			joined[join.Table] = true
+		}
+	}
+	var columns []string
+	fields := r.db.Mapper.TypeMap(t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("join")
+		if name == "" || !joined[name] {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("join field %s must be a struct", field.Name)
+		}
+		info := fields.GetByTraversal([]int{i})
+		if info == nil {
+			continue
+		}
+		joinColumns, err := database.GetColumns(reflect.New(fieldType).Interface())
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range joinColumns {
+			columns = append(columns, fmt.Sprintf("%s.%s AS %s", name, column, QuoteIdentifier(info.Path+"."+column)))
+		}
+	}
+	return columns, nil
+}
@@ -0,0 +1,142 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+func TestQualifyCondition(t *testing.T) {
+	join := database.Join{Type: constants.InnerJoin, Table: "users", On: "users.id = items.user_id"}
+	tests := []struct {
+		name      string
+		condition *database.CommonCondition
+		want      *database.CommonCondition
+	}{
+		{
+			name:      "without joins",
+			condition: &database.CommonCondition{Conditions: []database.Condition{{Field: "name", Value: "a", Op: constants.Equal}}},
+			want:      &database.CommonCondition{Conditions: []database.Condition{{Field: "name", Value: "a", Op: constants.Equal}}},
+		},
+		{
+			name: "qualifies fields and deleted_at",
+			condition: &database.CommonCondition{
+				Conditions: []database.Condition{
+					{Field: "name", Value: "a", Op: constants.Equal},
+					{Field: "users.email", Value: "b", Op: constants.Equal},
+				},
+				Sorting: []database.Sorting{{Field: "name", Order: "asc"}, {Field: "users.email", Order: "desc"}},
+				Joins:   []database.Join{join},
+			},
+			want: &database.CommonCondition{
+				Conditions: []database.Condition{
+					{Field: "items.name", Value: "a", Op: constants.Equal},
+					{Field: "users.email", Value: "b", Op: constants.Equal},
+					{Field: "items.deleted_at", Value: nil, Op: constants.Equal},
+				},
+				Sorting:         []database.Sorting{{Field: "items.name", Order: "asc"}, {Field: "users.email", Order: "desc"}},
+				Joins:           []database.Join{join},
+				IsSkipDeletedAt: true,
+			},
+		},
+		{
+			name: "keeps deleted rows when skipped",
+			condition: &database.CommonCondition{
+				Conditions:      []database.Condition{{Field: "name", Value: "a", Op: constants.Equal}},
+				Joins:           []database.Join{join},
+				IsSkipDeletedAt: true,
+			},
+			want: &database.CommonCondition{
+				Conditions:      []database.Condition{{Field: "items.name", Value: "a", Op: constants.Equal}},
+				Sorting:         []database.Sorting{},
+				Joins:           []database.Join{join},
+				IsSkipDeletedAt: true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qualifyCondition("items", tt.condition); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("qualifyCondition() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildJoins(t *testing.T) {
+	db, _ := newMockDB(t)
+	repo := NewRepository[item](db, "items").(*repository[item])
+	tests := []struct {
+		name    string
+		joins   []database.Join
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "inner join",
+			joins: []database.Join{{Table: "users", On: "users.id = items.user_id"}},
+			want:  "SELECT items.id FROM items INNER JOIN users ON users.id = items.user_id",
+		},
+		{
+			name:  "left join with schema and alias",
+			joins: []database.Join{{Type: constants.LeftJoin, Table: "auth.users", Alias: "u", On: "u.id = items.user_id"}},
+			want:  "SELECT items.id FROM items LEFT JOIN auth.users AS u ON u.id = items.user_id",
+		},
+		{
+			name:    "unsupported type",
+			joins:   []database.Join{{Type: "cross", Table: "users", On: "true"}},
+			wantErr: true,
+		},
+		{
+			name:    "table injection",
+			joins:   []database.Join{{Table: "users; DROP TABLE items", On: "true"}},
+			wantErr: true,
+		},
+		{
+			name:    "alias injection",
+			joins:   []database.Join{{Table: "users", Alias: "u ON true --", On: "true"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := repo.buildJoins(context.Background(), sq.Select("items.id").From("items"), tt.joins)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildJoins() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			query, _, err := builder.ToSql()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query != tt.want {
+				t.Errorf("query = %s, want %s", query, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteByConditionRejectsJoins(t *testing.T) {
+	db, _ := newMockDB(t)
+	condition := database.NewCommonCondition().
+		WithCondition("users.email", "a", constants.Equal).
+		InnerJoin("users", "users.id = items.user_id")
+	repos := map[string]database.BaseRepository[softItem]{
+		"repository": NewRepository[softItem](db, "items"),
+		"audited":    NewRepository[softItem](db, "items", WithAudit(NewAuditStore(db, "audit_logs"))),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			if err := repo.DeleteByCondition(context.Background(), condition); !errors.Is(err, ErrJoinNotSupported) {
+				t.Errorf("DeleteByCondition() = %v, want ErrJoinNotSupported", err)
+			}
+		})
+	}
+}
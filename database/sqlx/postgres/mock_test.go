@@ -0,0 +1,36 @@
+package sqlx_postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+type item struct {
+	ID   string `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+}
+
+type softItem struct {
+	ID        string     `db:"id" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at"`
+}
+
+// newMockDB returns a sqlx.DB whose statements are matched exactly against the expectations
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	raw, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		raw.Close()
+	})
+	return sqlx.NewDb(raw, "postgres"), mock
+}
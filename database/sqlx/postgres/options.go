@@ -0,0 +1,86 @@
+package sqlx_postgres
+
+import (
+	"time"
+
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type RepositoryOption func(*repositoryOptions)
+
+type repositoryOptions struct {
+	interceptors  []QueryInterceptor
+	resolver      *DBResolver
+	tenantColumn  string
+	tableResolver TableResolver
+	clock         func() time.Time
+	auditStore    database.AuditStore
+}
+
+// WithInterceptors adds interceptors run for the statements of the repository,
+// after the ones registered with RegisterQueryInterceptor
+func WithInterceptors(interceptors ...QueryInterceptor) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithResolver routes the reads of the repository with resolver, writes and transactions
+// go to resolver.Primary(), which replaces the db given to NewRepository
+func WithResolver(resolver *DBResolver) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.resolver = resolver
+	}
+}
+
+// WithTenantColumn scopes every query of the repository to the tenant stored in the
+// context with database.NewContextWithTenantID, queries without a tenant fail with
+// database.ErrTenantRequired. Created rows get the tenant in column.
+// Only the repository table and the preloaded entities having column are filtered: the
+// tables added with CommonCondition.Joins and the join tables of many to many relations
+// are not, the tenant must be part of their Join.On or of their owner rows.
+func WithTenantColumn(column string) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.tenantColumn = column
+	}
+}
+
+// WithTableResolver resolves the schema and table of every call with resolver instead of
+// using the table given to NewRepository, the result is quoted
+func WithTableResolver(resolver TableResolver) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.tableResolver = resolver
+	}
+}
+
+// WithClock replaces time.Now for the timestamps set by the repository
+func WithClock(clock func() time.Time) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.clock = clock
+	}
+}
+
+// WithAudit records the changes made by Create, CreateMany, Update, the deletes, Restore and
+// PurgeDeleted in store, in the transaction of the change
+func WithAudit(store database.AuditStore) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.auditStore = store
+	}
+}
+
+// getDB returns the db the writes go to, the primary of the resolver when one is set
+func (o repositoryOptions) getDB(db *sqlx.DB) *sqlx.DB {
+	if o.resolver != nil {
+		return o.resolver.Primary()
+	}
+	return db
+}
+
+func getRepositoryOptions(opts []RepositoryOption) repositoryOptions {
+	var options repositoryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
@@ -0,0 +1,250 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dotrongnhan/sharing-package/database"
+)
+
+// preloadBatchSize is the number of keys bound in one query, far below the 65535
+// parameters Postgres accepts in a statement
+const preloadBatchSize = 1000
+
+// joinRow is a row of a many to many join table
+type joinRow struct {
+	OwnerKey   string `db:"owner_key"`
+	RelatedKey string `db:"related_key"`
+}
+
+// preload loads the relations named by preloads on entities, one query per relation.
+// P is the entity or a projection of it.
+func preload[T any, P any](ctx context.Context, r *repository[T], entities []*P, preloads []string) error {
+	if len(preloads) == 0 || len(entities) == 0 {
+		return nil
+	}
+	values := make([]reflect.Value, 0, len(entities))
+	for _, entity := range entities {
+		if entity != nil {
+			values = append(values, reflect.ValueOf(entity).Elem())
+		}
+	}
+	return r.preloadValues(ctx, reflect.TypeOf((*P)(nil)).Elem(), values, preloads)
+}
+
+// preloadValues loads the relations of values, structs of type t. Relations nested with dots
+// are loaded on the related entities before they are assigned.
+func (r *repository[T]) preloadValues(ctx context.Context, t reflect.Type, values []reflect.Value, preloads []string) error {
+	var names []string
+	nested := make(map[string][]string)
+	for _, preload := range preloads {
+		name, rest, _ := strings.Cut(preload, ".")
+		if _, ok := nested[name]; !ok {
+			names = append(names, name)
+			nested[name] = nil
+		}
+		if rest != "" {
+			nested[name] = append(nested[name], rest)
+		}
+	}
+
+	for _, name := range names {
+		rel, err := database.GetRelation(t, name)
+		if err != nil {
+			return err
+		}
+		if err = r.loadRelation(ctx, rel, values, nested[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repository[T]) loadRelation(ctx context.Context, rel *database.Relation, values []reflect.Value, nested []string) error {
+	var ownerColumn, relatedColumn string
+	switch rel.Kind {
+	case database.RelationBelongsTo:
+		ownerColumn, relatedColumn = rel.ForeignKey, rel.References
+	case database.RelationHasOne, database.RelationHasMany:
+		ownerColumn, relatedColumn = rel.References, rel.ForeignKey
+	case database.RelationManyToMany:
+		ownerColumn, relatedColumn = rel.References, rel.RelatedReferences
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if key, ok := r.getKey(v, ownerColumn); ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// many to many go through the join table, ownerKeys maps a related key to its owners
+	relatedKeys := keys
+	ownerKeys := make(map[string][]string)
+	if rel.Kind == database.RelationManyToMany {
+		rows, err := r.selectJoinRows(ctx, rel, keys)
+		if err != nil {
+			return err
+		}
+		relatedKeys = nil
+		for _, row := range rows {
+			if _, ok := ownerKeys[row.RelatedKey]; !ok {
+				relatedKeys = append(relatedKeys, row.RelatedKey)
+			}
+			ownerKeys[row.RelatedKey] = append(ownerKeys[row.RelatedKey], row.OwnerKey)
+		}
+		if len(relatedKeys) == 0 {
+			return nil
+		}
+	}
+
+	related, err := r.selectRelated(ctx, rel, relatedColumn, relatedKeys)
+	if err != nil {
+		return err
+	}
+	if len(nested) > 0 {
+		elems := make([]reflect.Value, len(related))
+		for i, ptr := range related {
+			elems[i] = ptr.Elem()
+		}
+		if err = r.preloadValues(ctx, rel.Type, elems, nested); err != nil {
+			return err
+		}
+	}
+
+	byOwner := make(map[string][]reflect.Value)
+	for _, ptr := range related {
+		key, ok := r.getKey(ptr.Elem(), relatedColumn)
+		if !ok {
+			continue
+		}
+		if rel.Kind != database.RelationManyToMany {
+			byOwner[key] = append(byOwner[key], ptr)
+			continue
+		}
+		for _, owner := range ownerKeys[key] {
+			byOwner[owner] = append(byOwner[owner], ptr)
+		}
+	}
+	for _, v := range values {
+		key, ok := r.getKey(v, ownerColumn)
+		if !ok {
+			continue
+		}
+		assignRelation(v.FieldByName(rel.Name), byOwner[key])
+	}
+	return nil
+}
+
+// selectRelated returns pointers to the entities of rel whose column is in keys, selected
+// by batches of preloadBatchSize keys
+func (r *repository[T]) selectRelated(ctx context.Context, rel *database.Relation, column string, keys []string) ([]reflect.Value, error) {
+	table, err := r.resolveTable(ctx, rel.Table)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := database.GetColumns(reflect.New(rel.Type).Interface())
+	if err != nil {
+		return nil, err
+	}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	var results []reflect.Value
+	for _, batch := range chunks(keys, preloadBatchSize) {
+		db := psql.Select(columns...).
+			From(table).
+			Where(sq.Eq{column: batch})
+		if slices.Contains(columns, DeletedAtColumn) {
+			db = db.Where(sq.Eq{DeletedAtColumn: nil})
+		}
+		if slices.Contains(columns, r.options.tenantColumn) {
+			if db, err = r.scopeSelect(ctx, db); err != nil {
+				return nil, err
+			}
+		}
+		query, args, err := db.ToSql()
+		if err != nil {
+			return nil, err
+		}
+		dest := reflect.New(reflect.SliceOf(reflect.PointerTo(rel.Type)))
+		if err = Select(ctx, r.readDB(ctx), dest.Interface(), query, args...); err != nil {
+			return nil, err
+		}
+		for i := 0; i < dest.Elem().Len(); i++ {
+			results = append(results, dest.Elem().Index(i))
+		}
+	}
+	return results, nil
+}
+
+// selectJoinRows returns the rows of the join table of rel owned by keys, selected by
+// batches of preloadBatchSize keys. The join table is not filtered by the tenant column, the
+// owners of keys already are.
+func (r *repository[T]) selectJoinRows(ctx context.Context, rel *database.Relation, keys []string) ([]joinRow, error) {
+	table, err := r.resolveTable(ctx, rel.JoinTable)
+	if err != nil {
+		return nil, err
+	}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	var rows []joinRow
+	for _, batch := range chunks(keys, preloadBatchSize) {
+		query, args, err := psql.Select(
+			fmt.Sprintf("%s AS owner_key", rel.JoinForeignKey),
+			fmt.Sprintf("%s AS related_key", rel.JoinReferences),
+		).
+			From(table).
+			Where(sq.Eq{rel.JoinForeignKey: batch}).
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+		var batchRows []joinRow
+		if err = Select(ctx, r.readDB(ctx), &batchRows, query, args...); err != nil {
+			return nil, err
+		}
+		rows = append(rows, batchRows...)
+	}
+	return rows, nil
+}
+
+// getKey returns the value of column in the struct v as a string, false when it is nil
+func (r *repository[T]) getKey(v reflect.Value, column string) (string, bool) {
+	field := r.db.Mapper.FieldByName(v, column)
+	if !field.IsValid() {
+		return "", false
+	}
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return "", false
+		}
+		field = field.Elem()
+	}
+	return fmt.Sprint(field.Interface()), true
+}
+
+// assignRelation sets field, a pointer or a slice, to the related entity pointers
+func assignRelation(field reflect.Value, related []reflect.Value) {
+	if field.Kind() == reflect.Ptr {
+		if len(related) > 0 {
+			field.Set(related[0])
+		}
+		return
+	}
+	slice := reflect.MakeSlice(field.Type(), 0, len(related))
+	for _, ptr := range related {
+		if field.Type().Elem().Kind() == reflect.Ptr {
+			slice = reflect.Append(slice, ptr)
+		} else {
+			slice = reflect.Append(slice, ptr.Elem())
+		}
+	}
+	field.Set(slice)
+}
@@ -0,0 +1,122 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+)
+
+type tag struct {
+	ID   string `db:"id"`
+	Code string `db:"code"`
+}
+
+type customer struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+type order struct {
+	ID         string    `db:"id"`
+	CustomerID string    `db:"customer_id"`
+	Customer   *customer `db:"-" relation:"belongs_to" table:"customers" foreign_key:"customer_id"`
+	Tags       []*tag    `db:"-" relation:"many_to_many" table:"tags" join_table:"order_tags" join_foreign_key:"order_id" join_references:"tag_code" related_references:"code"`
+}
+
+func TestGetByIdWithPreloadsManyToMany(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRepository[order](db, "orders")
+	mock.ExpectQuery(`SELECT id, customer_id FROM orders WHERE id = $1`).
+		WithArgs("o1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id"}).AddRow("o1", "c1"))
+	mock.ExpectQuery(`SELECT order_id AS owner_key, tag_code AS related_key FROM order_tags WHERE order_id IN ($1)`).
+		WithArgs("o1").
+		WillReturnRows(sqlmock.NewRows([]string{"owner_key", "related_key"}).AddRow("o1", "red"))
+	// the related entities are matched on related_references, not on their id
+	mock.ExpectQuery(`SELECT id, code FROM tags WHERE code IN ($1)`).
+		WithArgs("red").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code"}).AddRow("t1", "red"))
+
+	result, err := repo.GetByIdWith(context.Background(), "o1", "Tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tags) != 1 || result.Tags[0].ID != "t1" {
+		t.Fatalf("Tags = %+v, want tag t1", result.Tags)
+	}
+}
+
+func TestGetByIdDoesNotPreload(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRepository[order](db, "orders")
+	mock.ExpectQuery(`SELECT id, customer_id FROM orders WHERE id = $1`).
+		WithArgs("o1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id"}).AddRow("o1", "c1"))
+	result, err := repo.GetById(context.Background(), "o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Customer != nil {
+		t.Fatal("GetById loaded a relation")
+	}
+}
+
+func TestPreloadBatchesKeys(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRepository[order](db, "orders")
+	count := preloadBatchSize + 1
+	orders := sqlmock.NewRows([]string{"id", "customer_id"})
+	for i := 0; i < count; i++ {
+		orders.AddRow(fmt.Sprintf("o%d", i), fmt.Sprintf("c%d", i))
+	}
+	mock.ExpectQuery(`SELECT id, customer_id FROM orders`).WillReturnRows(orders)
+	for _, batch := range chunks(make([]int, count), preloadBatchSize) {
+		query := `SELECT id, name FROM customers WHERE id IN (` + placeholders(len(batch)) + `)`
+		args := make([]driver.Value, len(batch))
+		for i := range args {
+			args[i] = sqlmock.AnyArg()
+		}
+		mock.ExpectQuery(query).WithArgs(args...).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	}
+	if _, err := repo.GetMany(context.Background(), database.NewCommonCondition().Preload("Customer")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	tests := []struct {
+		length int
+		want   []int
+	}{
+		{0, []int{}},
+		{2, []int{2}},
+		{3, []int{3}},
+		{7, []int{3, 3, 1}},
+		{9, []int{3, 3, 3}},
+	}
+	for _, tt := range tests {
+		got := chunks(make([]int, tt.length), 3)
+		lengths := make([]int, len(got))
+		for i, chunk := range got {
+			lengths[i] = len(chunk)
+		}
+		if fmt.Sprint(lengths) != fmt.Sprint(tt.want) {
+			t.Errorf("chunks(%d, 3) lengths = %v, want %v", tt.length, lengths, tt.want)
+		}
+	}
+}
+
+func placeholders(n int) string {
+	s := ""
+	for i := 1; i <= n; i++ {
+		if i > 1 {
+			s += ","
+		}
+		s += fmt.Sprintf("$%d", i)
+	}
+	return s
+}
@@ -0,0 +1,156 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+)
+
+var (
+	// ErrUnknownField is returned when a selected field is not a column of the entity
+	ErrUnknownField = errors.New("unknown field")
+	// ErrUnsupportedRepository is returned by GetManyAs for repositories not created by NewRepository
+	ErrUnsupportedRepository = errors.New("repository is not a sqlx postgres repository")
+)
+
+// GetManyAs returns the rows of repo matching condition scanned into P, a projection of T whose
+// columns must be columns of T. Only the columns of P are selected, narrowed to condition.Fields
+// when set.
+func GetManyAs[T any, P any](ctx context.Context, repo database.BaseRepository[T], condition *database.CommonCondition) (_ []*P, err error) {
+	base, ok := repo.(interface{ base() *repository[T] })
+	if !ok {
+		return nil, ErrUnsupportedRepository
+	}
+	r := base.base()
+	ctx, finish := r.instrument(ctx, "GetManyAs")
+	defer finish(&err)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return selectMany[T, P](ctx, r, table, getCondition(condition))
+}
+
+// base gives GetManyAs the repository behind the ones wrapping it
+func (r *repository[T]) base() *repository[T] {
+	return r
+}
+
+// selectMany returns the rows matching condition scanned into P, with their relations preloaded
+func selectMany[T any, P any](ctx context.Context, r *repository[T], table string, condition *database.CommonCondition) ([]*P, error) {
+	ctxLogger := logger.NewLogger(ctx)
+	condition, err := r.scopeCondition(ctx, condition)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
+		return nil, err
+	}
+	condition = qualifyCondition(table, condition)
+	columns, err := r.getSelectColumns(reflect.TypeOf((*P)(nil)).Elem(), table, condition)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while get columns", logger.ErrorKey, err)
+		return nil, err
+	}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	db := psql.Select(columns...).
+		From(table)
+	db, err = r.buildJoins(ctx, db, condition.Joins)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return nil, err
+	}
+	db, err = BuildQuery(db, condition)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return nil, err
+	}
+	query, args, err := db.ToSql()
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return nil, err
+	}
+	var results []*P
+	err = Select(ctx, r.readDB(ctx), &results, query, args...)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while select", logger.TableKey, r.table, logger.ErrorKey, err)
+		return nil, err
+	}
+	if err = preload(ctx, r, results, condition.Preloads); err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while preload", logger.TableKey, r.table, logger.ErrorKey, err)
+		return nil, err
+	}
+	return results, nil
+}
+
+// getSelectColumns returns the columns selected from table to scan rows into t, the entity
+// or a projection of it. With joins they are qualified and followed by the joined columns.
+func (r *repository[T]) getSelectColumns(t reflect.Type, table string, condition *database.CommonCondition) ([]string, error) {
+	columns, err := database.GetColumns(reflect.New(t).Interface())
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range columns {
+		if !r.columns[column] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, column)
+		}
+	}
+	if len(condition.Fields) > 0 {
+		if columns, err = getFields(t, columns, condition); err != nil {
+			return nil, err
+		}
+	}
+	if len(condition.Joins) == 0 {
+		return columns, nil
+	}
+	for i, column := range columns {
+		columns[i] = qualifyColumn(table, column)
+	}
+	joinColumns, err := r.getJoinColumns(t, condition.Joins)
+	if err != nil {
+		return nil, err
+	}
+	return append(columns, joinColumns...), nil
+}
+
+// getFields returns the fields of condition, checked against columns, followed by the
+// columns the preloaded relations of t are matched on
+func getFields(t reflect.Type, columns []string, condition *database.CommonCondition) ([]string, error) {
+	available := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		available[column] = true
+	}
+	selected := make(map[string]bool, len(condition.Fields))
+	fields := make([]string, 0, len(condition.Fields))
+	add := func(field string) {
+		if !selected[field] {
+			selected[field] = true
+			fields = append(fields, field)
+		}
+	}
+	for _, field := range condition.Fields {
+		if !available[field] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		add(field)
+	}
+	for _, preload := range condition.Preloads {
+		name, _, _ := strings.Cut(preload, ".")
+		rel, err := database.GetRelation(t, name)
+		if err != nil {
+			return nil, err
+		}
+		column := rel.References
+		if rel.Kind == database.RelationBelongsTo {
+			column = rel.ForeignKey
+		}
+		if available[column] {
+			add(column)
+		}
+	}
+	return fields, nil
+}
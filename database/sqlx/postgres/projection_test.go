@@ -0,0 +1,75 @@
+package sqlx_postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+type orderSummary struct {
+	ID       string    `db:"id"`
+	Customer *customer `db:"customer" join:"c"`
+}
+
+type orderWithTotal struct {
+	ID    string  `db:"id"`
+	Total float64 `db:"total"`
+}
+
+func TestGetSelectColumns(t *testing.T) {
+	db, _ := newMockDB(t)
+	repo := NewRepository[order](db, "orders").(*repository[order])
+	join := database.Join{Type: constants.LeftJoin, Table: "customers", Alias: "c", On: "c.id = orders.customer_id"}
+	tests := []struct {
+		name      string
+		t         reflect.Type
+		condition *database.CommonCondition
+		want      []string
+		wantErr   error
+	}{
+		{
+			name:      "columns of the projection",
+			t:         reflect.TypeOf(orderSummary{}),
+			condition: database.NewCommonCondition(),
+			want:      []string{"id"},
+		},
+		{
+			name:      "selected fields with the preload keys",
+			t:         reflect.TypeOf(order{}),
+			condition: database.NewCommonCondition().WithFields("id").Preload("Customer"),
+			want:      []string{"id", "customer_id"},
+		},
+		{
+			name:      "qualified columns with the joined columns",
+			t:         reflect.TypeOf(orderSummary{}),
+			condition: database.NewCommonCondition().WithJoin(join),
+			want:      []string{"orders.id", `c.id AS "customer.id"`, `c.name AS "customer.name"`},
+		},
+		{
+			name:      "unknown column of the projection",
+			t:         reflect.TypeOf(orderWithTotal{}),
+			condition: database.NewCommonCondition(),
+			wantErr:   ErrUnknownField,
+		},
+		{
+			name:      "unknown field",
+			t:         reflect.TypeOf(order{}),
+			condition: database.NewCommonCondition().WithFields("id", "1; DROP TABLE orders"),
+			wantErr:   ErrUnknownField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.getSelectColumns(tt.t, "orders", tt.condition)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("getSelectColumns() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getSelectColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+	"github.com/dotrongnhan/sharing-package/pkg/metrics"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	statementKey = "statement"
+	argsKey      = "args"
+	argTypesKey  = "arg_types"
+	rowsKey      = "rows"
+	planKey      = "plan"
+	replicaKey   = "replica"
+)
+
+type QueryLogConfig struct {
+	// SlowThreshold is the duration over which a query is logged as a warning and counted
+	// in metrics.SlowQueries, zero disables slow query detection
+	SlowThreshold time.Duration
+	// LogQueries logs every query at debug level, otherwise only slow and failed queries are logged
+	LogQueries bool
+	// LogArgs adds the number and the types of the query arguments to the entries, their
+	// values may hold passwords or tokens and are only logged for ArgValueTables
+	LogArgs bool
+	// ArgValueTables are the repository tables whose query argument values are logged,
+	// redacted with logger.Redact, when LogArgs is set
+	ArgValueTables []string
+	// Explain logs the EXPLAIN plan of slow queries
+	Explain bool
+}
+
+var queryLogConfig atomic.Pointer[QueryLogConfig]
+
+// EnableQueryLogging logs the statements run by Select, Insert, InsertMultiple, Exec and Delete
+func EnableQueryLogging(cfg QueryLogConfig) {
+	queryLogConfig.Store(&cfg)
+}
+
+func DisableQueryLogging() {
+	queryLogConfig.Store(nil)
+}
+
+// queryLogInterceptor logs statements according to the config set with EnableQueryLogging
+type queryLogInterceptor struct{}
+
+func (queryLogInterceptor) BeforeQuery(ctx context.Context, _ *Query) (context.Context, error) {
+	return ctx, nil
+}
+
+func (queryLogInterceptor) AfterQuery(ctx context.Context, query *Query, result *QueryResult) {
+	cfg := queryLogConfig.Load()
+	if cfg == nil {
+		return
+	}
+	slow := cfg.SlowThreshold > 0 && result.Duration > cfg.SlowThreshold
+	if !slow && result.Err == nil && !cfg.LogQueries {
+		return
+	}
+
+	keyvals := []interface{}{
+		statementKey, query.Statement,
+		logger.DurationKey, float64(result.Duration.Microseconds()) / 1000,
+		rowsKey, result.Rows,
+	}
+	if query.Table != "" {
+		keyvals = append(keyvals, logger.TableKey, query.Table)
+	}
+	if cfg.LogArgs {
+		keyvals = append(keyvals, argTypesKey, getArgTypes(query.Args))
+		if query.Table != "" && slices.Contains(cfg.ArgValueTables, query.Table) {
+			keyvals = append(keyvals, argsKey, logger.Redact(query.Args))
+		}
+	}
+
+	ctxLogger := logger.NewLogger(ctx)
+	switch {
+	case result.Err != nil:
+		ctxLogger.Errorw(append(keyvals, logger.MsgKey, "Query failed", logger.ErrorKey, result.Err)...)
+	case slow:
+		metrics.SlowQueries.WithLabelValues(query.Operation).Inc()
+		if cfg.Explain {
+			plan, explainErr := explain(ctx, query.DB, query.Statement, query.Args)
+			if explainErr != nil {
+				keyvals = append(keyvals, logger.ErrorKey, explainErr)
+			} else {
+				keyvals = append(keyvals, planKey, plan)
+			}
+		}
+		ctxLogger.Warnw(append(keyvals, logger.MsgKey, "Slow query")...)
+	default:
+		ctxLogger.Debugw(append(keyvals, logger.MsgKey, "Query executed")...)
+	}
+}
+
+// explainSavepoint isolates the EXPLAIN run in the transaction of the query, so a failed
+// EXPLAIN does not abort the transaction of the caller
+const explainSavepoint = "query_log_explain"
+
+// explain returns the plan of query without executing it
+func explain(ctx context.Context, db *sqlx.DB, query string, args []interface{}) (string, error) {
+	tx := GetContextTransaction(ctx)
+	if tx == nil {
+		rows, err := db.QueryContext(ctx, "EXPLAIN "+query, args...)
+		if err != nil {
+			return "", err
+		}
+		return readPlan(rows)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+explainSavepoint); err != nil {
+		return "", err
+	}
+	rows, err := tx.QueryContext(ctx, "EXPLAIN "+query, args...)
+	var plan string
+	if err == nil {
+		plan, err = readPlan(rows)
+	}
+	if err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+explainSavepoint); rbErr != nil {
+			return "", errors.Join(err, rbErr)
+		}
+		return "", err
+	}
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+explainSavepoint)
+	return plan, err
+}
+
+func readPlan(rows *sql.Rows) (string, error) {
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+// getArgTypes returns the Go types of args, to log them without their values
+func getArgTypes(args []interface{}) []string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = fmt.Sprintf("%T", arg)
+	}
+	return types
+}
+
+// getOperation returns the SQL command of query, in lower case
+func getOperation(query string) string {
+	operation, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return strings.ToLower(operation)
+}
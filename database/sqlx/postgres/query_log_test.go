@@ -0,0 +1,93 @@
+package sqlx_postgres
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+)
+
+// captureQueryLogs enables the query logging with cfg and returns the buffer the entries go to
+func captureQueryLogs(t *testing.T, cfg QueryLogConfig) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	if err := logger.Configure(logger.Config{Outputs: []io.Writer{&out}}); err != nil {
+		t.Fatal(err)
+	}
+	EnableQueryLogging(cfg)
+	t.Cleanup(func() {
+		DisableQueryLogging()
+		_ = logger.Configure(logger.Config{})
+	})
+	return &out
+}
+
+func TestQueryLogArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		tables     []string
+		wantValues bool
+	}{
+		{name: "types only"},
+		{name: "other table allowed", tables: []string{"users"}},
+		{name: "table allowed", tables: []string{"items"}, wantValues: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureQueryLogs(t, QueryLogConfig{LogQueries: true, LogArgs: true, ArgValueTables: tt.tables})
+			db, mock := newMockDB(t)
+			repo := NewRepository[item](db, "items")
+			mock.ExpectQuery(`SELECT id, name FROM items WHERE name = $1`).
+				WithArgs("s3cret").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+			condition := database.NewCommonCondition().WithCondition("name", "s3cret", constants.Equal)
+			if _, err := repo.GetMany(context.Background(), condition); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), `"arg_types":["string"]`) {
+				t.Errorf("arg types not logged: %s", out.String())
+			}
+			if got := strings.Contains(out.String(), "s3cret"); got != tt.wantValues {
+				t.Errorf("values logged = %v, want %v: %s", got, tt.wantValues, out.String())
+			}
+		})
+	}
+}
+
+func TestExplainInSavepoint(t *testing.T) {
+	out := captureQueryLogs(t, QueryLogConfig{SlowThreshold: time.Microsecond, Explain: true})
+	db, mock := newMockDB(t)
+	repo := NewRepository[item](db, "items")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, name FROM items`).
+		WillDelayFor(time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	mock.ExpectExec(`SAVEPOINT query_log_explain`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`EXPLAIN SELECT id, name FROM items`).WillReturnError(errors.New("explain failed"))
+	// the transaction of the caller is usable again once rolled back to the savepoint
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT query_log_explain`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	tm := database.NewTransactionManager(db)
+	ctx, err := tm.BeginTransaction(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.GetMany(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = tm.CommitTransaction(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Slow query") {
+		t.Errorf("slow query not logged: %s", out.String())
+	}
+}
@@ -3,6 +3,7 @@ package sqlx_postgres
 import (
 	"context"
 	"errors"
+
 	sq "github.com/Masterminds/squirrel"
 	"github.com/dotrongnhan/sharing-package/database"
 	"github.com/dotrongnhan/sharing-package/pkg/constants"
@@ -11,44 +12,81 @@ import (
 )
 
 type repository[T any] struct {
-	db    *sqlx.DB
-	table string
+	db      *sqlx.DB
+	table   string
+	options repositoryOptions
+	// columns are the columns of the entity, softDelete tells whether it has deleted_at
+	columns    map[string]bool
+	softDelete bool
 }
 
-func NewRepository[T any](db *sqlx.DB, table string) database.BaseRepository[T] {
-	return &repository[T]{
-		db:    db,
-		table: table,
+func NewRepository[T any](db *sqlx.DB, table string, opts ...RepositoryOption) database.BaseRepository[T] {
+	columns, _ := database.GetColumnsGeneric[T]()
+	options := getRepositoryOptions(opts)
+	r := &repository[T]{
+		db:      options.getDB(db),
+		table:   table,
+		options: options,
+		columns: make(map[string]bool, len(columns)),
+	}
+	for _, column := range columns {
+		r.columns[column] = true
+	}
+	r.softDelete = r.columns[DeletedAtColumn]
+	if r.options.auditStore != nil {
+		return &auditedRepository[T]{
+			repository: r,
+			store:      r.options.auditStore,
+		}
 	}
+	return r
 }
 
-func (r *repository[T]) CountByCondition(ctx context.Context, condition *database.CommonCondition) (uint64, error) {
+func (r *repository[T]) CountByCondition(ctx context.Context, condition *database.CommonCondition) (_ uint64, err error) {
+	ctx, finish := r.instrument(ctx, "CountByCondition")
+	defer finish(&err)
 	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return 0, err
+	}
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 	condition = getCondition(condition)
 	db := psql.Select("count(*)").
 		Where("").
-		From(r.table)
-	newCondition := &database.CommonCondition{
-		Conditions: condition.Conditions,
-		Paging:     nil,
+		From(table)
+	newCondition, err := r.scopeCondition(ctx, &database.CommonCondition{
+		Conditions:      condition.Conditions,
+		Paging:          nil,
+		IsSkipDeletedAt: condition.IsSkipDeletedAt,
+		Joins:           condition.Joins,
+	})
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
+		return 0, err
+	}
+	newCondition = qualifyCondition(table, newCondition)
+	db, err = r.buildJoins(ctx, db, newCondition.Joins)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return 0, err
 	}
-	db, err := BuildQuery(db, newCondition)
+	db, err = BuildQuery(db, newCondition)
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
 		return 0, err
 	}
 	query, args, err := db.ToSql()
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
 		return 0, err
 	}
 	var total []struct {
 		Count uint64 `db:"count"`
 	}
-	err = Select(ctx, r.db, &total, query, args...)
+	err = Select(ctx, r.readDB(ctx), &total, query, args...)
 	if err != nil {
-		ctxLogger.Errorf("Failed while get total %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while get total", logger.TableKey, r.table, logger.ErrorKey, err)
 		return 0, err
 	}
 	return total[0].Count, nil
@@ -62,34 +100,32 @@ func (r *repository[T]) TableName() string {
 	return r.table
 }
 
-func (r *repository[T]) GetByCondition(ctx context.Context, condition *database.CommonCondition) (*database.Pagination[T], error) {
-	ctxLogger := logger.NewLogger(ctx)
-	condition = getCondition(condition)
-	total, err := r.CountByCondition(ctx, condition)
-	if err != nil {
-		ctxLogger.Errorf("Failed while get total, err: %v", err)
-		return nil, err
+// readDB returns the connection the reads of ctx go to
+func (r *repository[T]) readDB(ctx context.Context) *sqlx.DB {
+	if r.options.resolver == nil {
+		return r.db
 	}
-	meta := database.GetMetaPagination(total, condition.Paging)
+	return r.options.resolver.Read(ctx)
+}
 
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	columns, err := database.GetColumnsGeneric[T]()
-	db := psql.Select(columns...).
-		From(r.table)
-	db, err = BuildQuery(db, condition)
+func (r *repository[T]) GetByCondition(ctx context.Context, condition *database.CommonCondition) (_ *database.Pagination[T], err error) {
+	ctx, finish := r.instrument(ctx, "GetByCondition")
+	defer finish(&err)
+	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
 		return nil, err
 	}
-	query, args, err := db.ToSql()
+	condition = getCondition(condition)
+	total, err := r.CountByCondition(ctx, condition)
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while get total", logger.ErrorKey, err)
 		return nil, err
 	}
-	var results []*T
-	err = Select(ctx, r.db, &results, query, args...)
+	meta := database.GetMetaPagination(total, condition.Paging)
+
+	results, err := selectMany[T, T](ctx, r, table, condition)
 	if err != nil {
-		ctxLogger.Errorf("Failed while select %s, err: %v", r.table, err)
 		return nil, err
 	}
 	return &database.Pagination[T]{
@@ -98,73 +134,103 @@ func (r *repository[T]) GetByCondition(ctx context.Context, condition *database.
 	}, nil
 }
 
-func (r *repository[T]) GetMany(ctx context.Context, condition *database.CommonCondition) ([]*T, error) {
-	ctxLogger := logger.NewLogger(ctx)
+func (r *repository[T]) GetMany(ctx context.Context, condition *database.CommonCondition) (_ []*T, err error) {
+	ctx, finish := r.instrument(ctx, "GetMany")
+	defer finish(&err)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return nil, err
+	}
 	condition = getCondition(condition)
+	return selectMany[T, T](ctx, r, table, condition)
+}
 
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	columns, err := database.GetColumnsGeneric[T]()
-	db := psql.Select(columns...).
-		From(r.table)
-	db, err = BuildQuery(db, condition)
+func (r *repository[T]) GetById(ctx context.Context, id string) (_ *T, err error) {
+	ctx, finish := r.instrument(ctx, "GetById")
+	defer finish(&err)
+	return r.getById(ctx, id, nil)
+}
+
+func (r *repository[T]) GetByIdWith(ctx context.Context, id string, preloads ...string) (_ *T, err error) {
+	ctx, finish := r.instrument(ctx, "GetByIdWith")
+	defer finish(&err)
+	return r.getById(ctx, id, preloads)
+}
+
+func (r *repository[T]) getById(ctx context.Context, id string, preloads []string) (*T, error) {
+	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
 		return nil, err
 	}
-	query, args, err := db.ToSql()
+	columns, err := database.GetColumnsGeneric[T]()
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while get columns", logger.ErrorKey, err)
 		return nil, err
 	}
-	var results []*T
-	err = Select(ctx, r.db, &results, query, args...)
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	db := psql.Select(columns...).
+		From(table).
+		Where(sq.Eq{"id": id})
+	db = r.notDeleted(db)
+	db, err = r.scopeSelect(ctx, db)
 	if err != nil {
-		ctxLogger.Errorf("Failed while select %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
 		return nil, err
 	}
-	return results, nil
-}
-
-func (r *repository[T]) GetById(ctx context.Context, id string) (*T, error) {
-	ctxLogger := logger.NewLogger(ctx)
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	db := psql.Select("*").
-		From(r.table).
-		Where(sq.Eq{"id": id}).
-		Where(sq.Eq{"deleted_at": nil})
 	query, args, err := db.ToSql()
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
 		return nil, err
 	}
 	var results []*T
-	err = Select(ctx, r.db, &results, query, args...)
+	err = Select(ctx, r.readDB(ctx), &results, query, args...)
 	if err != nil {
-		ctxLogger.Errorf("Failed while select %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while select", logger.TableKey, r.table, logger.ErrorKey, err)
 		return nil, err
 	}
 	if len(results) == 0 {
 		return nil, nil
 	}
+	if err = preload(ctx, r, results, preloads); err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while preload", logger.TableKey, r.table, logger.ErrorKey, err)
+		return nil, err
+	}
 	return results[0], nil
 }
 
-func (r *repository[T]) GetByIds(ctx context.Context, ids []string) ([]*T, error) {
+func (r *repository[T]) GetByIds(ctx context.Context, ids []string) (_ []*T, err error) {
+	ctx, finish := r.instrument(ctx, "GetByIds")
+	defer finish(&err)
 	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := database.GetColumnsGeneric[T]()
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while get columns", logger.ErrorKey, err)
+		return nil, err
+	}
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	db := psql.Select("*").
-		From(r.table).
-		Where(sq.Eq{"id": ids}).
-		Where(sq.Eq{"deleted_at": nil})
+	db := psql.Select(columns...).
+		From(table).
+		Where(sq.Eq{"id": ids})
+	db = r.notDeleted(db)
+	db, err = r.scopeSelect(ctx, db)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
+		return nil, err
+	}
 	query, args, err := db.ToSql()
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
 		return nil, err
 	}
 	var results []*T
-	err = Select(ctx, r.db, &results, query, args...)
+	err = Select(ctx, r.readDB(ctx), &results, query, args...)
 	if err != nil {
-		ctxLogger.Errorf("Failed while select %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while select", logger.TableKey, r.table, logger.ErrorKey, err)
 		return nil, err
 	}
 	if len(results) == 0 {
@@ -173,37 +239,51 @@ func (r *repository[T]) GetByIds(ctx context.Context, ids []string) ([]*T, error
 	return results, nil
 }
 
-func (r *repository[T]) Create(ctx context.Context, entity *T) (*T, error) {
+func (r *repository[T]) Create(ctx context.Context, entity *T) (_ *T, err error) {
+	ctx, finish := r.instrument(ctx, "Create")
+	defer finish(&err)
 	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if createInterface, ok := any(entity).(BeforeCreateInterface); ok {
 		if err := createInterface.BeforeCreate(ctx, r.db); err != nil {
-			ctxLogger.Errorf("Failed while execute BeforeCreate, err: %v", err)
+			ctxLogger.Errorw(logger.MsgKey, "Failed while execute BeforeCreate", logger.ErrorKey, err)
 			return nil, err
 		}
 	}
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 	columns, values, err := database.GetColumnsAndValues(entity)
 	if err != nil {
-		ctxLogger.Errorf("Failed while get columns and values, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while get columns and values", logger.ErrorKey, err)
+		return nil, err
+	}
+	valuesList := [][]interface{}{values}
+	columns, err = r.setTenant(ctx, columns, valuesList)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
 		return nil, err
 	}
-	db := psql.Insert(r.table).
+	columns = r.setCreated(ctx, columns, valuesList)
+	values = valuesList[0]
+	db := psql.Insert(table).
 		Columns(columns...).
 		Values(values...)
 	query, args, err := db.ToSql()
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
 		return nil, err
 	}
 	id, err := Insert(ctx, r.db, query, args...)
 	if err != nil {
-		ctxLogger.Errorf("Failed while insert %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while insert", logger.TableKey, r.table, logger.ErrorKey, err)
 		return nil, err
 	}
 	condition := database.NewCommonCondition().WithCondition("id", id, constants.Equal)
-	results, err := r.GetByCondition(ctx, condition)
+	results, err := r.GetByCondition(NewContextWithPrimary(ctx), condition)
 	if err != nil {
-		ctxLogger.Errorf("Failed while get %s by condition, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while get by condition", logger.TableKey, r.table, logger.ErrorKey, err)
 		return nil, err
 	}
 	if results == nil || len(results.Data) == 0 {
@@ -212,8 +292,14 @@ func (r *repository[T]) Create(ctx context.Context, entity *T) (*T, error) {
 	return results.Data[0], nil
 }
 
-func (r *repository[T]) CreateMany(ctx context.Context, entities []*T) ([]string, error) {
+func (r *repository[T]) CreateMany(ctx context.Context, entities []*T) (_ []string, err error) {
+	ctx, finish := r.instrument(ctx, "CreateMany")
+	defer finish(&err)
 	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return nil, err
+	}
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	if len(entities) == 0 {
@@ -224,7 +310,7 @@ func (r *repository[T]) CreateMany(ctx context.Context, entities []*T) ([]string
 	for i, e := range entities {
 		if createInterface, ok := any(e).(BeforeCreateInterface); ok {
 			if err := createInterface.BeforeCreate(ctx, r.db); err != nil {
-				ctxLogger.Errorf("Failed while execute BeforeCreate, err: %v", err)
+				ctxLogger.Errorw(logger.MsgKey, "Failed while execute BeforeCreate", logger.ErrorKey, err)
 				return nil, err
 			}
 		}
@@ -234,11 +320,17 @@ func (r *repository[T]) CreateMany(ctx context.Context, entities []*T) ([]string
 	// Lấy cột từ bản ghi đầu tiên
 	columns, valuesList, err := database.GetColumnsAndValuesForMany(models)
 	if err != nil {
-		ctxLogger.Errorf("Failed while get columns and values, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while get columns and values", logger.ErrorKey, err)
 		return nil, err
 	}
+	columns, err = r.setTenant(ctx, columns, valuesList)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
+		return nil, err
+	}
+	columns = r.setCreated(ctx, columns, valuesList)
 
-	db := psql.Insert(r.table).
+	db := psql.Insert(table).
 		Columns(columns...)
 	for _, values := range valuesList {
 		db = db.Values(values...)
@@ -246,153 +338,138 @@ func (r *repository[T]) CreateMany(ctx context.Context, entities []*T) ([]string
 
 	query, args, err := db.ToSql()
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
 		return nil, err
 	}
 
 	ids, err := InsertMultiple(ctx, r.db, query, args...)
 	if err != nil {
-		ctxLogger.Errorf("Failed while insert %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while insert", logger.TableKey, r.table, logger.ErrorKey, err)
 		return nil, err
 	}
 
 	return ids, nil
 }
 
-func (r *repository[T]) Update(ctx context.Context, id string, entity *T) error {
+func (r *repository[T]) Update(ctx context.Context, id string, entity *T) (err error) {
+	ctx, finish := r.instrument(ctx, "Update")
+	defer finish(&err)
 	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return err
+	}
 	if updateInterface, ok := any(entity).(BeforeUpdateInterface); ok {
 		if err := updateInterface.BeforeUpdate(ctx, r.db); err != nil {
-			ctxLogger.Errorf("Failed while execute BeforeUpdate, err: %v", err)
+			ctxLogger.Errorw(logger.MsgKey, "Failed while execute BeforeUpdate", logger.ErrorKey, err)
 			return err
 		}
 	}
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 	columns, values, err := database.GetColumnsAndValues(entity)
 	if err != nil {
-		ctxLogger.Errorf("Failed while get columns and values, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while get columns and values", logger.ErrorKey, err)
 		return err
 	}
-	db := psql.Update(r.table)
+	db := psql.Update(table)
 	for i, column := range columns {
-		if column == "id" {
+		if r.isManagedColumn(ctx, column) {
 			continue
 		}
 		db = db.Set(column, values[i])
 	}
+	db = r.setUpdated(ctx, db)
 	db = db.Where(sq.Eq{"id": id})
-	query, args, err := db.ToSql()
-	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
-		return err
-	}
-	err = Exec(ctx, r.db, query, args...)
+	db, err = r.scopeUpdate(ctx, db)
 	if err != nil {
-		ctxLogger.Errorf("Failed while update %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
 		return err
 	}
-	return nil
-}
-
-func (r *repository[T]) Delete(ctx context.Context, id string) error {
-	ctxLogger := logger.NewLogger(ctx)
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	db := psql.Update(r.table).
-		Where(sq.Eq{"id": id})
-	var entity T
-	if deleteInterface, ok := any(&entity).(BeforeDeleteInterface); ok {
-		if err := deleteInterface.BeforeDelete(ctx, r.db, &db); err != nil {
-			ctxLogger.Errorf("Failed while execute BeforeDelete, err: %v", err)
-			return err
-		}
-	}
 	query, args, err := db.ToSql()
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
 		return err
 	}
 	err = Exec(ctx, r.db, query, args...)
 	if err != nil {
-		ctxLogger.Errorf("Failed while delete %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while update", logger.TableKey, r.table, logger.ErrorKey, err)
 		return err
 	}
 	return nil
 }
 
-func (r *repository[T]) DeleteMany(ctx context.Context, ids []string) error {
-	ctxLogger := logger.NewLogger(ctx)
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	db := psql.Update(r.table).
-		Where(sq.Eq{"id": ids})
-	var entity T
-	if deleteInterface, ok := any(&entity).(BeforeDeleteInterface); ok {
-		if err := deleteInterface.BeforeDelete(ctx, r.db, &db); err != nil {
-			ctxLogger.Errorf("Failed while execute BeforeDelete, err: %v", err)
-			return err
-		}
-	}
-	query, args, err := db.ToSql()
+func (r *repository[T]) Delete(ctx context.Context, id string) (err error) {
+	ctx, finish := r.instrument(ctx, "Delete")
+	defer finish(&err)
+	table, err := r.getTable(ctx)
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
 		return err
 	}
-	err = Exec(ctx, r.db, query, args...)
+	return r.deleteWhere(ctx, table, sq.Eq{"id": id})
+}
+
+func (r *repository[T]) DeleteMany(ctx context.Context, ids []string) (err error) {
+	ctx, finish := r.instrument(ctx, "DeleteMany")
+	defer finish(&err)
+	table, err := r.getTable(ctx)
 	if err != nil {
-		ctxLogger.Errorf("Failed while delete %s, err: %v", r.table, err)
 		return err
 	}
-	return nil
+	return r.deleteWhere(ctx, table, sq.Eq{"id": ids})
 }
 
-func (r *repository[T]) DeleteByCondition(ctx context.Context, condition *database.CommonCondition) error {
+func (r *repository[T]) DeleteByCondition(ctx context.Context, condition *database.CommonCondition) (err error) {
+	ctx, finish := r.instrument(ctx, "DeleteByCondition")
+	defer finish(&err)
 	ctxLogger := logger.NewLogger(ctx)
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	condition = getCondition(condition)
-
-	db := psql.Update(r.table)
-	db, err := BuildUpdateConditions(db, condition.Conditions)
+	table, err := r.getTable(ctx)
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
 		return err
 	}
-	var entity T
-	if deleteInterface, ok := any(&entity).(BeforeDeleteInterface); ok {
-		if err = deleteInterface.BeforeDelete(ctx, r.db, &db); err != nil {
-			ctxLogger.Errorf("Failed while execute BeforeDelete, err: %v", err)
-			return err
-		}
+	condition = getCondition(condition)
+	if len(condition.Conditions) == 0 {
+		return ErrEmptyCondition
 	}
-	query, args, err := db.ToSql()
-	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
-		return err
+	if len(condition.Joins) > 0 {
+		return ErrJoinNotSupported
 	}
-	err = Exec(ctx, r.db, query, args...)
+	pred, err := BuildPredicates(condition.Conditions)
 	if err != nil {
-		ctxLogger.Errorf("Failed while delete %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
 		return err
 	}
-	return nil
+	return r.deleteWhere(ctx, table, pred)
 }
 
-func (r *repository[T]) ExistById(ctx context.Context, id string) (bool, error) {
+func (r *repository[T]) ExistById(ctx context.Context, id string) (_ bool, err error) {
+	ctx, finish := r.instrument(ctx, "ExistById")
+	defer finish(&err)
 	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return false, err
+	}
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 	db := psql.Select("count(*)").
-		From(r.table).
-		Where(sq.Eq{"id": id}).
-		Where(sq.Eq{"deleted_at": nil})
+		From(table).
+		Where(sq.Eq{"id": id})
+	db = r.notDeleted(db)
+	db, err = r.scopeSelect(ctx, db)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
+		return false, err
+	}
 	query, args, err := db.ToSql()
 	if err != nil {
-		ctxLogger.Errorf("Failed while build query, err: %v", err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
 		return false, err
 	}
 	var total []struct {
 		Count uint64 `db:"count"`
 	}
-	err = Select(ctx, r.db, &total, query, args...)
+	err = Select(ctx, r.readDB(ctx), &total, query, args...)
 	if err != nil {
-		ctxLogger.Errorf("Failed while select %s, err: %v", r.table, err)
+		ctxLogger.Errorw(logger.MsgKey, "Failed while select", logger.TableKey, r.table, logger.ErrorKey, err)
 		return false, err
 	}
 	return total[0].Count > 0, nil
@@ -0,0 +1,168 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+type ReplicaPolicy int
+
+const (
+	// ReplicaPolicyRoundRobin spreads reads evenly over the healthy replicas
+	ReplicaPolicyRoundRobin ReplicaPolicy = iota
+	// ReplicaPolicyLeastLoaded sends reads to the healthy replica with the fewest connections in use
+	ReplicaPolicyLeastLoaded
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+)
+
+type ResolverConfig struct {
+	Policy ReplicaPolicy
+	// HealthCheckInterval is the time between two pings of the replicas, defaults to 10s.
+	// A negative value disables health checks.
+	HealthCheckInterval time.Duration
+	// HealthCheckTimeout defaults to 2s
+	HealthCheckTimeout time.Duration
+}
+
+type replica struct {
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// DBResolver routes reads to the replicas and everything else to the primary.
+// Reads fall back to the primary when no replica is healthy.
+type DBResolver struct {
+	primary  *sqlx.DB
+	replicas []*replica
+	config   ResolverConfig
+	next     atomic.Uint64
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDBResolver starts the health checks of the replicas, Close stops them
+func NewDBResolver(primary *sqlx.DB, replicas []*sqlx.DB, config *ResolverConfig) *DBResolver {
+	if config == nil {
+		config = &ResolverConfig{}
+	}
+	r := &DBResolver{
+		primary: primary,
+		config:  *config,
+		stop:    make(chan struct{}),
+	}
+	if r.config.HealthCheckInterval == 0 {
+		r.config.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if r.config.HealthCheckTimeout <= 0 {
+		r.config.HealthCheckTimeout = defaultHealthCheckTimeout
+	}
+	for _, db := range replicas {
+		rep := &replica{db: db}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+	if len(r.replicas) > 0 && r.config.HealthCheckInterval > 0 {
+		go r.healthCheck()
+	}
+	return r
+}
+
+func (r *DBResolver) Primary() *sqlx.DB {
+	return r.primary
+}
+
+// Read returns the connection reads of ctx should use: the primary when ctx carries
+// a transaction or was created with NewContextWithPrimary, a healthy replica otherwise
+func (r *DBResolver) Read(ctx context.Context) *sqlx.DB {
+	if GetContextTransaction(ctx) != nil || IsContextPrimary(ctx) {
+		return r.primary
+	}
+	if db := r.pickReplica(); db != nil {
+		return db
+	}
+	return r.primary
+}
+
+func (r *DBResolver) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+func (r *DBResolver) pickReplica() *sqlx.DB {
+	switch r.config.Policy {
+	case ReplicaPolicyLeastLoaded:
+		var picked *sqlx.DB
+		minInUse := -1
+		for _, rep := range r.replicas {
+			if !rep.healthy.Load() {
+				continue
+			}
+			if inUse := rep.db.Stats().InUse; minInUse == -1 || inUse < minInUse {
+				picked, minInUse = rep.db, inUse
+			}
+		}
+		return picked
+	default:
+		n := uint64(len(r.replicas))
+		start := r.next.Add(1)
+		for i := uint64(0); i < n; i++ {
+			if rep := r.replicas[(start+i)%n]; rep.healthy.Load() {
+				return rep.db
+			}
+		}
+		return nil
+	}
+}
+
+func (r *DBResolver) healthCheck() {
+	ticker := time.NewTicker(r.config.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			for i, rep := range r.replicas {
+				r.checkReplica(i, rep)
+			}
+		}
+	}
+}
+
+func (r *DBResolver) checkReplica(index int, rep *replica) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.HealthCheckTimeout)
+	defer cancel()
+	err := rep.db.PingContext(ctx)
+	healthy := err == nil
+	if rep.healthy.Swap(healthy) == healthy {
+		return
+	}
+	ctxLogger := logger.NewLogger(ctx)
+	if healthy {
+		ctxLogger.Infow(logger.MsgKey, "Replica is back to healthy", replicaKey, index)
+	} else {
+		ctxLogger.Warnw(logger.MsgKey, "Replica is unhealthy", replicaKey, index, logger.ErrorKey, err)
+	}
+}
+
+// NewContextWithPrimary makes the repositories read from the primary, to read
+// the rows written just before without replication lag
+func NewContextWithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, constants.ContextKeyDBPrimary, true)
+}
+
+func IsContextPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(constants.ContextKeyDBPrimary).(bool)
+	return primary
+}
@@ -0,0 +1,36 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestWithResolverRouting(t *testing.T) {
+	db, _ := newMockDB(t)
+	primary, primaryMock := newMockDB(t)
+	replica, replicaMock := newMockDB(t)
+	resolver := NewDBResolver(primary, []*sqlx.DB{replica}, &ResolverConfig{HealthCheckInterval: -1})
+	t.Cleanup(resolver.Close)
+	// the db given to NewRepository is replaced by the primary of the resolver
+	repo := NewRepository[item](db, "items", WithResolver(resolver))
+
+	replicaMock.ExpectQuery(`SELECT id, name FROM items WHERE name = $1`).
+		WithArgs("a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "a"))
+	primaryMock.ExpectExec(`UPDATE items SET name = $1 WHERE id = $2`).
+		WithArgs("b", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	if _, err := repo.GetMany(ctx, database.NewCommonCondition().WithCondition("name", "a", constants.Equal)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Update(ctx, "1", &item{ID: "1", Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+}
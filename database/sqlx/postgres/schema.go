@@ -0,0 +1,94 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+)
+
+// ErrNoTransaction is returned by SetSearchPath when ctx carries no transaction
+var ErrNoTransaction = errors.New("no transaction found in context")
+
+// publicSchema follows the resolved schema in the search_path, for the shared objects as the extensions
+const publicSchema = "public"
+
+// TableResolver returns the schema and the table the statements of a call on table go to.
+// An empty schema leaves the table unqualified, resolved through the search_path. Within a
+// transaction, the search_path is set to the schema followed by public, so the names left
+// unqualified by the statements of the call, as in the join clauses, resolve to it as well.
+type TableResolver func(ctx context.Context, table string) (schema string, name string, err error)
+
+// SchemaPerTenant routes every call to the schema prefix + tenant ID, the tenant being
+// read with database.GetTenantID. Calls without a tenant fail with database.ErrTenantRequired.
+func SchemaPerTenant(prefix string) TableResolver {
+	return func(ctx context.Context, table string) (string, string, error) {
+		tenantID := database.GetTenantID(ctx)
+		if tenantID == "" {
+			return "", "", database.ErrTenantRequired
+		}
+		return prefix + tenantID, table, nil
+	}
+}
+
+// QuoteIdentifier quotes name so it can be used as a Postgres identifier whatever it contains
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteTable returns the quoted table, qualified by schema when it is not empty
+func QuoteTable(schema, table string) string {
+	if schema == "" {
+		return QuoteIdentifier(table)
+	}
+	return QuoteIdentifier(schema) + "." + QuoteIdentifier(table)
+}
+
+// SetSearchPath sets the search_path of the context transaction. It uses SET LOCAL so the
+// setting ends with the transaction and never leaks to the next user of the pooled connection.
+func SetSearchPath(ctx context.Context, schemas ...string) error {
+	tx := GetContextTransaction(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+	quoted := make([]string, len(schemas))
+	for i, schema := range schemas {
+		quoted[i] = QuoteIdentifier(schema)
+	}
+	_, err := tx.ExecContext(ctx, "SET LOCAL search_path TO "+strings.Join(quoted, ", "))
+	return err
+}
+
+// getTable returns the table the statements of ctx go to, the table given to NewRepository
+// is used as is unless a TableResolver is set
+func (r *repository[T]) getTable(ctx context.Context) (string, error) {
+	if r.options.tableResolver == nil {
+		return r.table, nil
+	}
+	ctxLogger := logger.NewLogger(ctx)
+	schema, name, err := r.options.tableResolver(ctx, r.table)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while resolve table", logger.TableKey, r.table, logger.ErrorKey, err)
+		return "", err
+	}
+	if schema != "" && GetContextTransaction(ctx) != nil {
+		if err = SetSearchPath(ctx, schema, publicSchema); err != nil {
+			ctxLogger.Errorw(logger.MsgKey, "Failed while set search path", logger.TableKey, r.table, logger.ErrorKey, err)
+			return "", err
+		}
+	}
+	return QuoteTable(schema, name), nil
+}
+
+func (r *repository[T]) resolveTable(ctx context.Context, table string) (string, error) {
+	if r.options.tableResolver == nil {
+		return table, nil
+	}
+	schema, name, err := r.options.tableResolver(ctx, table)
+	if err != nil {
+		return "", err
+	}
+	return QuoteTable(schema, name), nil
+}
@@ -0,0 +1,74 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+)
+
+func TestQuoteTable(t *testing.T) {
+	tests := []struct {
+		schema, table, want string
+	}{
+		{table: "items", want: `"items"`},
+		{schema: "tenant_a", table: "items", want: `"tenant_a"."items"`},
+		{schema: `a"b`, table: "items", want: `"a""b"."items"`},
+	}
+	for _, tt := range tests {
+		if got := QuoteTable(tt.schema, tt.table); got != tt.want {
+			t.Errorf("QuoteTable(%q, %q) = %s, want %s", tt.schema, tt.table, got, tt.want)
+		}
+	}
+}
+
+func TestSchemaPerTenant(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   bool
+	}{
+		{name: "without transaction"},
+		{name: "sets the search path in the transaction", tx: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			repo := NewRepository[item](db, "items", WithTableResolver(SchemaPerTenant("tenant_")))
+			ctx := database.NewContextWithTenantID(context.Background(), "a")
+			if tt.tx {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SET LOCAL search_path TO "tenant_a", "public"`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			mock.ExpectQuery(`SELECT id, name FROM "tenant_a"."items" WHERE id = $1`).
+				WithArgs("1").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "a"))
+			if tt.tx {
+				mock.ExpectCommit()
+				tm := database.NewTransactionManager(db)
+				var err error
+				if ctx, err = tm.BeginTransaction(ctx); err != nil {
+					t.Fatal(err)
+				}
+				defer func() {
+					if err := tm.CommitTransaction(ctx); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			if _, err := repo.GetById(ctx, "1"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSchemaPerTenantRequiresTenant(t *testing.T) {
+	db, _ := newMockDB(t)
+	repo := NewRepository[item](db, "items", WithTableResolver(SchemaPerTenant("tenant_")))
+	if _, err := repo.GetById(context.Background(), "1"); !errors.Is(err, database.ErrTenantRequired) {
+		t.Errorf("GetById() = %v, want ErrTenantRequired", err)
+	}
+}
@@ -0,0 +1,188 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+)
+
+const (
+	DeletedAtColumn = "deleted_at"
+	DeletedByColumn = "deleted_by"
+)
+
+var (
+	// ErrSoftDeleteNotSupported is returned by Delete, DeleteMany and DeleteByCondition when the
+	// entity has no deleted_at column nor BeforeDelete hook, and by Restore, GetDeleted and
+	// PurgeDeleted when it has no deleted_at column, whatever its hook. Rows of such entities
+	// are removed with HardDelete only.
+	ErrSoftDeleteNotSupported = errors.New("entity does not support soft delete")
+	// ErrEmptyCondition is returned by DeleteByCondition when the condition would match every row
+	ErrEmptyCondition = errors.New("delete condition is required")
+	// ErrJoinNotSupported is returned by DeleteByCondition when the condition has joins
+	ErrJoinNotSupported = errors.New("delete condition does not support joins")
+)
+
+// deleteWhere soft deletes the rows matching pred, stamping deleted_by with the actor of ctx
+// when the entity has it. Entities implementing BeforeDeleteInterface set the columns themselves.
+func (r *repository[T]) deleteWhere(ctx context.Context, table string, pred sq.Sqlizer) error {
+	ctxLogger := logger.NewLogger(ctx)
+	var entity T
+	deleteInterface, hasHook := any(&entity).(BeforeDeleteInterface)
+	if !r.softDelete && !hasHook {
+		return ErrSoftDeleteNotSupported
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	db := psql.Update(table).
+		Where(pred)
+	if r.softDelete {
+		db = db.Where(sq.Eq{DeletedAtColumn: nil})
+	}
+	db, err := r.scopeUpdate(ctx, db)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
+		return err
+	}
+	if hasHook {
+		if err = deleteInterface.BeforeDelete(ctx, r.db, &db); err != nil {
+			ctxLogger.Errorw(logger.MsgKey, "Failed while execute BeforeDelete", logger.ErrorKey, err)
+			return err
+		}
+	} else {
+		db = db.Set(DeletedAtColumn, r.now())
+		if actorID := database.GetActorID(ctx); r.columns[DeletedByColumn] && actorID != "" {
+			db = db.Set(DeletedByColumn, actorID)
+		}
+	}
+	query, args, err := db.ToSql()
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return err
+	}
+	err = Exec(ctx, r.db, query, args...)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while delete", logger.TableKey, r.table, logger.ErrorKey, err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository[T]) hardDeleteWhere(ctx context.Context, table string, pred sq.Sqlizer) error {
+	ctxLogger := logger.NewLogger(ctx)
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	db := psql.Delete(table).
+		Where(pred)
+	db, err := r.scopeDelete(ctx, db)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
+		return err
+	}
+	query, args, err := db.ToSql()
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return err
+	}
+	err = Delete(ctx, r.db, query, args...)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while delete", logger.TableKey, r.table, logger.ErrorKey, err)
+		return err
+	}
+	return nil
+}
+
+// HardDelete removes the row whether it is soft deleted or not
+func (r *repository[T]) HardDelete(ctx context.Context, id string) (err error) {
+	ctx, finish := r.instrument(ctx, "HardDelete")
+	defer finish(&err)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return err
+	}
+	return r.hardDeleteWhere(ctx, table, sq.Eq{"id": id})
+}
+
+// Restore clears deleted_at, and deleted_by when the entity has it, of a soft deleted row
+func (r *repository[T]) Restore(ctx context.Context, id string) (err error) {
+	ctx, finish := r.instrument(ctx, "Restore")
+	defer finish(&err)
+	if !r.softDelete {
+		return ErrSoftDeleteNotSupported
+	}
+	ctxLogger := logger.NewLogger(ctx)
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return err
+	}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	db := psql.Update(table).
+		Set(DeletedAtColumn, nil).
+		Where(sq.Eq{"id": id}).
+		Where(sq.NotEq{DeletedAtColumn: nil})
+	if r.columns[DeletedByColumn] {
+		db = db.Set(DeletedByColumn, nil)
+	}
+	db, err = r.scopeUpdate(ctx, db)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while scope tenant", logger.ErrorKey, err)
+		return err
+	}
+	query, args, err := db.ToSql()
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while build query", logger.ErrorKey, err)
+		return err
+	}
+	err = Exec(ctx, r.db, query, args...)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while restore", logger.TableKey, r.table, logger.ErrorKey, err)
+		return err
+	}
+	return nil
+}
+
+// GetDeleted returns the soft deleted rows matching condition
+func (r *repository[T]) GetDeleted(ctx context.Context, condition *database.CommonCondition) (_ []*T, err error) {
+	ctx, finish := r.instrument(ctx, "GetDeleted")
+	defer finish(&err)
+	if !r.softDelete {
+		return nil, ErrSoftDeleteNotSupported
+	}
+	condition = getCondition(condition)
+	deleted := *condition
+	deleted.IsSkipDeletedAt = true
+	deleted.Conditions = make([]database.Condition, 0, len(condition.Conditions)+1)
+	deleted.Conditions = append(deleted.Conditions, condition.Conditions...)
+	deleted.Conditions = append(deleted.Conditions, database.Condition{
+		Field: DeletedAtColumn,
+		Value: nil,
+		Op:    constants.NotEqual,
+	})
+	return r.GetMany(ctx, &deleted)
+}
+
+// PurgeDeleted removes the rows soft deleted before the given time
+func (r *repository[T]) PurgeDeleted(ctx context.Context, before time.Time) (err error) {
+	ctx, finish := r.instrument(ctx, "PurgeDeleted")
+	defer finish(&err)
+	if !r.softDelete {
+		return ErrSoftDeleteNotSupported
+	}
+	table, err := r.getTable(ctx)
+	if err != nil {
+		return err
+	}
+	return r.hardDeleteWhere(ctx, table, sq.Lt{DeletedAtColumn: before})
+}
+
+// notDeleted filters out the soft deleted rows when the entity supports soft delete
+func (r *repository[T]) notDeleted(db sq.SelectBuilder) sq.SelectBuilder {
+	if !r.softDelete {
+		return db
+	}
+	return db.Where(sq.Eq{DeletedAtColumn: nil})
+}
@@ -0,0 +1,67 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+func TestDeleteByConditionRejectsEmptyCondition(t *testing.T) {
+	db, _ := newMockDB(t)
+	repo := NewRepository[softItem](db, "items")
+	for _, condition := range []*database.CommonCondition{nil, database.NewCommonCondition()} {
+		if err := repo.DeleteByCondition(context.Background(), condition); !errors.Is(err, ErrEmptyCondition) {
+			t.Errorf("DeleteByCondition(%v) = %v, want ErrEmptyCondition", condition, err)
+		}
+	}
+}
+
+func TestDeleteWithoutSoftDelete(t *testing.T) {
+	db, _ := newMockDB(t)
+	repo := NewRepository[item](db, "items")
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		delete func() error
+	}{
+		{"Delete", func() error { return repo.Delete(ctx, "1") }},
+		{"DeleteMany", func() error { return repo.DeleteMany(ctx, []string{"1"}) }},
+		{"DeleteByCondition", func() error {
+			return repo.DeleteByCondition(ctx, database.NewCommonCondition().WithCondition("name", "a", constants.Equal))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.delete(); !errors.Is(err, ErrSoftDeleteNotSupported) {
+				t.Errorf("got %v, want ErrSoftDeleteNotSupported", err)
+			}
+		})
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRepository[softItem](db, "items")
+	mock.ExpectExec(`UPDATE items SET deleted_at = $1 WHERE (name = $2) AND deleted_at IS NULL`).
+		WithArgs(sqlmock.AnyArg(), "a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	condition := database.NewCommonCondition().WithCondition("name", "a", constants.Equal)
+	if err := repo.DeleteByCondition(context.Background(), condition); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHardDelete(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRepository[item](db, "items")
+	mock.ExpectExec(`DELETE FROM items WHERE id = $1`).
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.HardDelete(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,79 @@
+package sqlx_postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+// getTenantID returns the tenant of ctx when the repository is tenant scoped,
+// failing with database.ErrTenantRequired when ctx has none
+func (r *repository[T]) getTenantID(ctx context.Context) (string, error) {
+	if r.options.tenantColumn == "" {
+		return "", nil
+	}
+	tenantID := database.GetTenantID(ctx)
+	if tenantID == "" {
+		return "", database.ErrTenantRequired
+	}
+	return tenantID, nil
+}
+
+// scopeCondition returns a copy of condition filtered by the tenant of ctx, the deleted_at
+// filter is dropped for entities without soft delete
+func (r *repository[T]) scopeCondition(ctx context.Context, condition *database.CommonCondition) (*database.CommonCondition, error) {
+	tenantID, err := r.getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *condition
+	if !r.softDelete {
+		scoped.IsSkipDeletedAt = true
+	}
+	if tenantID == "" {
+		return &scoped, nil
+	}
+	scoped.Conditions = make([]database.Condition, 0, len(condition.Conditions)+1)
+	scoped.Conditions = append(scoped.Conditions, condition.Conditions...)
+	scoped.Conditions = append(scoped.Conditions, database.Condition{
+		Field: r.options.tenantColumn,
+		Value: tenantID,
+		Op:    constants.Equal,
+	})
+	return &scoped, nil
+}
+
+func (r *repository[T]) scopeSelect(ctx context.Context, db sq.SelectBuilder) (sq.SelectBuilder, error) {
+	tenantID, err := r.getTenantID(ctx)
+	if err != nil || tenantID == "" {
+		return db, err
+	}
+	return db.Where(sq.Eq{r.options.tenantColumn: tenantID}), nil
+}
+
+func (r *repository[T]) scopeUpdate(ctx context.Context, db sq.UpdateBuilder) (sq.UpdateBuilder, error) {
+	tenantID, err := r.getTenantID(ctx)
+	if err != nil || tenantID == "" {
+		return db, err
+	}
+	return db.Where(sq.Eq{r.options.tenantColumn: tenantID}), nil
+}
+
+func (r *repository[T]) scopeDelete(ctx context.Context, db sq.DeleteBuilder) (sq.DeleteBuilder, error) {
+	tenantID, err := r.getTenantID(ctx)
+	if err != nil || tenantID == "" {
+		return db, err
+	}
+	return db.Where(sq.Eq{r.options.tenantColumn: tenantID}), nil
+}
+
+// setTenant sets the tenant column of the inserted rows to the tenant of ctx
+func (r *repository[T]) setTenant(ctx context.Context, columns []string, valuesList [][]interface{}) ([]string, error) {
+	tenantID, err := r.getTenantID(ctx)
+	if err != nil || tenantID == "" {
+		return columns, err
+	}
+	return setColumn(columns, valuesList, r.options.tenantColumn, tenantID), nil
+}
@@ -0,0 +1,239 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+type tenantItem struct {
+	ID        string     `db:"id" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	TenantID  string     `db:"tenant_id" json:"tenant_id"`
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at"`
+}
+
+func TestTenantScope(t *testing.T) {
+	columns := []string{"id", "name", "tenant_id", "deleted_at"}
+	row := func() *sqlmock.Rows { return sqlmock.NewRows(columns).AddRow("1", "a", "t", nil) }
+	byName := func() *database.CommonCondition {
+		return database.NewCommonCondition().WithCondition("name", "a", constants.Equal)
+	}
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		call   func(ctx context.Context, repo database.BaseRepository[tenantItem]) error
+	}{
+		{
+			name: "GetById",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, name, tenant_id, deleted_at FROM items WHERE id = $1 AND deleted_at IS NULL AND tenant_id = $2`).
+					WithArgs("1", "t").WillReturnRows(row())
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := repo.GetById(ctx, "1")
+				return err
+			},
+		},
+		{
+			name: "GetByIds",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, name, tenant_id, deleted_at FROM items WHERE id IN ($1) AND deleted_at IS NULL AND tenant_id = $2`).
+					WithArgs("1", "t").WillReturnRows(row())
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := repo.GetByIds(ctx, []string{"1"})
+				return err
+			},
+		},
+		{
+			name: "GetMany",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, name, tenant_id, deleted_at FROM items WHERE name = $1 AND tenant_id = $2 AND deleted_at IS NULL`).
+					WithArgs("a", "t").WillReturnRows(row())
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := repo.GetMany(ctx, byName())
+				return err
+			},
+		},
+		{
+			name: "GetByCondition",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count(*) FROM items WHERE name = $1 AND tenant_id = $2 AND deleted_at IS NULL`).
+					WithArgs("a", "t").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT id, name, tenant_id, deleted_at FROM items WHERE name = $1 AND tenant_id = $2 AND deleted_at IS NULL`).
+					WithArgs("a", "t").WillReturnRows(row())
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := repo.GetByCondition(ctx, byName())
+				return err
+			},
+		},
+		{
+			name: "CountByCondition",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count(*) FROM items WHERE name = $1 AND tenant_id = $2 AND deleted_at IS NULL`).
+					WithArgs("a", "t").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := repo.CountByCondition(ctx, byName())
+				return err
+			},
+		},
+		{
+			name: "ExistById",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count(*) FROM items WHERE id = $1 AND deleted_at IS NULL AND tenant_id = $2`).
+					WithArgs("1", "t").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := repo.ExistById(ctx, "1")
+				return err
+			},
+		},
+		{
+			name: "Update",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE items SET name = $1 WHERE id = $2 AND tenant_id = $3`).
+					WithArgs("b", "1", "t").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				return repo.Update(ctx, "1", &tenantItem{ID: "1", Name: "b", TenantID: "other"})
+			},
+		},
+		{
+			name: "Delete",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE items SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL AND tenant_id = $3`).
+					WithArgs(sqlmock.AnyArg(), "1", "t").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				return repo.Delete(ctx, "1")
+			},
+		},
+		{
+			name: "DeleteByCondition",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE items SET deleted_at = $1 WHERE (name = $2) AND deleted_at IS NULL AND tenant_id = $3`).
+					WithArgs(sqlmock.AnyArg(), "a", "t").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				return repo.DeleteByCondition(ctx, byName())
+			},
+		},
+		{
+			name: "HardDelete",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM items WHERE id = $1 AND tenant_id = $2`).
+					WithArgs("1", "t").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				return repo.HardDelete(ctx, "1")
+			},
+		},
+		{
+			name: "Restore",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE items SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NOT NULL AND tenant_id = $3`).
+					WithArgs(nil, "1", "t").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				return repo.Restore(ctx, "1")
+			},
+		},
+		{
+			name: "GetDeleted",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, name, tenant_id, deleted_at FROM items WHERE name = $1 AND deleted_at IS NOT NULL AND tenant_id = $2`).
+					WithArgs("a", "t").WillReturnRows(row())
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := repo.GetDeleted(ctx, byName())
+				return err
+			},
+		},
+		{
+			name: "PurgeDeleted",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`DELETE FROM items WHERE deleted_at < $1 AND tenant_id = $2`).
+					WithArgs(sqlmock.AnyArg(), "t").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				return repo.PurgeDeleted(ctx, time.Now())
+			},
+		},
+		{
+			name: "Aggregate",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT(*) AS "count" FROM items WHERE tenant_id = $1 AND deleted_at IS NULL`).
+					WithArgs("t").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := Aggregate[tenantItem, struct {
+					Count int64 `db:"count"`
+				}](ctx, repo, database.NewAggregateCondition().Count("*", ""))
+				return err
+			},
+		},
+		{
+			name: "Create",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO items (id,name,tenant_id,deleted_at) VALUES ($1,$2,$3,$4) RETURNING id`).
+					WithArgs("1", "a", "t", nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+				mock.ExpectQuery(`SELECT count(*) FROM items WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`).
+					WithArgs("1", "t").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT id, name, tenant_id, deleted_at FROM items WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`).
+					WithArgs("1", "t").WillReturnRows(row())
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := repo.Create(ctx, &tenantItem{ID: "1", Name: "a", TenantID: "other"})
+				return err
+			},
+		},
+		{
+			name: "CreateMany",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO items (id,name,tenant_id,deleted_at) VALUES ($1,$2,$3,$4),($5,$6,$7,$8) RETURNING id`).
+					WithArgs("1", "a", "t", nil, "2", "b", "t", nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1").AddRow("2"))
+			},
+			call: func(ctx context.Context, repo database.BaseRepository[tenantItem]) error {
+				_, err := repo.CreateMany(ctx, []*tenantItem{{ID: "1", Name: "a"}, {ID: "2", Name: "b", TenantID: "other"}})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			repo := NewRepository[tenantItem](db, "items", WithTenantColumn("tenant_id"))
+			tt.expect(mock)
+			if err := tt.call(database.NewContextWithTenantID(context.Background(), "t"), repo); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestTenantScopeRequiresTenant(t *testing.T) {
+	db, _ := newMockDB(t)
+	repo := NewRepository[tenantItem](db, "items", WithTenantColumn("tenant_id"))
+	ctx := context.Background()
+	calls := map[string]func() error{
+		"GetById": func() error { _, err := repo.GetById(ctx, "1"); return err },
+		"Update":  func() error { return repo.Update(ctx, "1", &tenantItem{ID: "1"}) },
+		"Delete":  func() error { return repo.Delete(ctx, "1") },
+		"Create":  func() error { _, err := repo.Create(ctx, &tenantItem{ID: "1"}); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, database.ErrTenantRequired) {
+			t.Errorf("%s() = %v, want ErrTenantRequired", name, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dotrongnhan/sharing-package/database"
+)
+
+const (
+	CreatedAtColumn = "created_at"
+	UpdatedAtColumn = "updated_at"
+	CreatedByColumn = "created_by"
+	UpdatedByColumn = "updated_by"
+)
+
+func (r *repository[T]) now() time.Time {
+	if r.options.clock != nil {
+		return r.options.clock()
+	}
+	return time.Now()
+}
+
+// setCreated fills created_at and updated_at with the clock, created_by and updated_by with
+// the actor of ctx, for the columns the entity has
+func (r *repository[T]) setCreated(ctx context.Context, columns []string, valuesList [][]interface{}) []string {
+	now := r.now()
+	for _, column := range []string{CreatedAtColumn, UpdatedAtColumn} {
+		if r.columns[column] {
+			columns = setColumn(columns, valuesList, column, now)
+		}
+	}
+	if actorID := database.GetActorID(ctx); actorID != "" {
+		for _, column := range []string{CreatedByColumn, UpdatedByColumn} {
+			if r.columns[column] {
+				columns = setColumn(columns, valuesList, column, actorID)
+			}
+		}
+	}
+	return columns
+}
+
+// setUpdated sets updated_at and updated_by, for the columns the entity has
+func (r *repository[T]) setUpdated(ctx context.Context, db sq.UpdateBuilder) sq.UpdateBuilder {
+	if r.columns[UpdatedAtColumn] {
+		db = db.Set(UpdatedAtColumn, r.now())
+	}
+	if actorID := database.GetActorID(ctx); actorID != "" && r.columns[UpdatedByColumn] {
+		db = db.Set(UpdatedByColumn, actorID)
+	}
+	return db
+}
+
+// isManagedColumn tells whether column is set by the repository rather than by Update, the
+// actor columns are kept from the entity unless setUpdated writes them from ctx
+func (r *repository[T]) isManagedColumn(ctx context.Context, column string) bool {
+	switch column {
+	case "id", DeletedAtColumn, CreatedAtColumn, UpdatedAtColumn:
+		return true
+	case UpdatedByColumn:
+		return database.GetActorID(ctx) != ""
+	}
+	// rows can not be moved to another tenant
+	return column == r.options.tenantColumn
+}
+
+// setColumn sets column to value in every row of valuesList, adding the column when missing
+func setColumn(columns []string, valuesList [][]interface{}, column string, value interface{}) []string {
+	for i, c := range columns {
+		if c == column {
+			for _, values := range valuesList {
+				values[i] = value
+			}
+			return columns
+		}
+	}
+	for i := range valuesList {
+		valuesList[i] = append(valuesList[i], value)
+	}
+	return append(columns, column)
+}
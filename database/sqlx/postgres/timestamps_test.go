@@ -0,0 +1,57 @@
+package sqlx_postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dotrongnhan/sharing-package/database"
+)
+
+type auditedItem struct {
+	ID        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	CreatedBy string    `db:"created_by" json:"created_by"`
+	UpdatedBy string    `db:"updated_by" json:"updated_by"`
+}
+
+func TestUpdateActorColumns(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		actor string
+		query string
+		args  []driver.Value
+	}{
+		{
+			name:  "keeps the actor columns of the entity without an actor",
+			query: `UPDATE audited_items SET name = $1, created_by = $2, updated_by = $3, updated_at = $4 WHERE id = $5`,
+			args:  []driver.Value{"b", "creator", "hook", now, "1"},
+		},
+		{
+			name:  "sets updated_by from the actor",
+			actor: "actor",
+			query: `UPDATE audited_items SET name = $1, created_by = $2, updated_at = $3, updated_by = $4 WHERE id = $5`,
+			args:  []driver.Value{"b", "creator", now, "actor", "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			repo := NewRepository[auditedItem](db, "audited_items", WithClock(func() time.Time { return now }))
+			ctx := context.Background()
+			if tt.actor != "" {
+				ctx = database.NewContextWithActorID(ctx, tt.actor)
+			}
+			mock.ExpectExec(tt.query).WithArgs(tt.args...).WillReturnResult(sqlmock.NewResult(0, 1))
+			entity := &auditedItem{ID: "1", Name: "b", CreatedBy: "creator", UpdatedBy: "hook"}
+			if err := repo.Update(ctx, "1", entity); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
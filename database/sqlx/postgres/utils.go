@@ -13,6 +13,8 @@ import (
 	"strings"
 )
 
+const uniqueViolationCode = "23505"
+
 func GetContextTransaction(ctx context.Context) *sql.Tx {
 	if ctx.Value(constants.ContextKeyDBTransaction) != nil {
 		return ctx.Value(constants.ContextKeyDBTransaction).(*sql.Tx)
@@ -20,6 +22,11 @@ func GetContextTransaction(ctx context.Context) *sql.Tx {
 	return nil
 }
 
+// withoutTransaction returns a ctx whose statements run on the pool instead of the context transaction
+func withoutTransaction(ctx context.Context) context.Context {
+	return context.WithValue(ctx, constants.ContextKeyDBTransaction, (*sql.Tx)(nil))
+}
+
 func txSelect(tx *sql.Tx, dest interface{}, query string, args ...interface{}) error {
 	rows, err := tx.Query(query, args...)
 	if err != nil {
@@ -33,31 +40,37 @@ func txSelect(tx *sql.Tx, dest interface{}, query string, args ...interface{}) e
 }
 
 func Select(ctx context.Context, db *sqlx.DB, dest interface{}, query string, args ...interface{}) error {
-	tx := GetContextTransaction(ctx)
-	if tx != nil {
-		err := txSelect(tx, dest, query, args...)
-		if err != nil {
-			return err
+	return runQuery(ctx, db, OperationSelect, query, args, func(ctx context.Context, query string, args []interface{}) (int64, error) {
+		tx := GetContextTransaction(ctx)
+		var err error
+		if tx != nil {
+			err = txSelect(tx, dest, query, args...)
+		} else {
+			err = db.Select(dest, query, args...)
 		}
-	} else {
-		err := db.Select(dest, query, args...)
 		if err != nil {
-			return err
+			return 0, err
 		}
-	}
-	return nil
+		return countRows(dest), nil
+	})
 }
 
 func Insert(ctx context.Context, db *sqlx.DB, query string, args ...interface{}) (*string, error) {
-	tx := GetContextTransaction(ctx)
 	queryS := fmt.Sprintf("%s %s", query, "RETURNING id")
 	var id string
-	var err error
-	if tx != nil {
-		err = tx.QueryRow(queryS, args...).Scan(&id)
-	} else {
-		err = db.QueryRow(queryS, args...).Scan(&id)
-	}
+	err := runQuery(ctx, db, OperationInsert, queryS, args, func(ctx context.Context, query string, args []interface{}) (int64, error) {
+		tx := GetContextTransaction(ctx)
+		var err error
+		if tx != nil {
+			err = tx.QueryRow(query, args...).Scan(&id)
+		} else {
+			err = db.QueryRow(query, args...).Scan(&id)
+		}
+		if err != nil {
+			return 0, err
+		}
+		return 1, nil
+	})
 	if err != nil {
 		var mErr *mysql.MySQLError
 		if errors.As(err, &mErr) {
@@ -69,15 +82,31 @@ func Insert(ctx context.Context, db *sqlx.DB, query string, args ...interface{})
 }
 
 func InsertMultiple(ctx context.Context, db *sqlx.DB, query string, args ...interface{}) ([]string, error) {
-	tx := GetContextTransaction(ctx)
 	queryS := fmt.Sprintf("%s %s", query, "RETURNING id")
-	var rows *sql.Rows
-	var err error
-	if tx != nil {
-		rows, err = tx.Query(queryS, args...)
-	} else {
-		rows, err = db.Query(queryS, args...)
-	}
+	ids := make([]string, 0)
+	err := runQuery(ctx, db, OperationInsert, queryS, args, func(ctx context.Context, query string, args []interface{}) (int64, error) {
+		tx := GetContextTransaction(ctx)
+		var rows *sql.Rows
+		var err error
+		if tx != nil {
+			rows, err = tx.Query(query, args...)
+		} else {
+			rows, err = db.Query(query, args...)
+		}
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id string
+			err = rows.Scan(&id)
+			if err != nil {
+				return int64(len(ids)), err
+			}
+			ids = append(ids, id)
+		}
+		return int64(len(ids)), rows.Err()
+	})
 	if err != nil {
 		var mErr *mysql.MySQLError
 		if errors.As(err, &mErr) {
@@ -85,38 +114,49 @@ func InsertMultiple(ctx context.Context, db *sqlx.DB, query string, args ...inte
 		}
 		return nil, err
 	}
-	ids := make([]string, 0)
-	for rows.Next() {
-		var id string
-		err := rows.Scan(&id)
-		if err != nil {
-			return nil, err
-		}
-		ids = append(ids, id)
-	}
 	return ids, nil
 }
 
 func Exec(ctx context.Context, db *sqlx.DB, query string, args ...interface{}) error {
-	tx := GetContextTransaction(ctx)
-	var err error
-	if tx != nil {
-		_, err = tx.Exec(query, args...)
-	} else {
-		_, err = db.Exec(query, args...)
-	}
-	return err
+	return runQuery(ctx, db, getOperation(query), query, args, func(ctx context.Context, query string, args []interface{}) (int64, error) {
+		return execContext(ctx, db, query, args)
+	})
 }
 
 func Delete(ctx context.Context, db *sqlx.DB, query string, args ...interface{}) error {
+	return runQuery(ctx, db, OperationDelete, query, args, func(ctx context.Context, query string, args []interface{}) (int64, error) {
+		return execContext(ctx, db, query, args)
+	})
+}
+
+// execContext runs query in the context transaction when there is one and returns the affected rows
+func execContext(ctx context.Context, db *sqlx.DB, query string, args []interface{}) (int64, error) {
 	tx := GetContextTransaction(ctx)
+	var res sql.Result
 	var err error
 	if tx != nil {
-		_, err = tx.Exec(query, args...)
+		res, err = tx.Exec(query, args...)
 	} else {
-		_, err = db.Exec(query, args...)
+		res, err = db.Exec(query, args...)
+	}
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := res.RowsAffected()
+	return rows, nil
+}
+
+// IsUniqueViolation reports whether err was caused by a unique constraint violation.
+// Drivers exposing SQLState (pq, pgx) are checked by code, others by message.
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	var sqlStateErr interface{ SQLState() string }
+	if errors.As(err, &sqlStateErr) {
+		return sqlStateErr.SQLState() == uniqueViolationCode
 	}
-	return err
+	return strings.Contains(err.Error(), uniqueViolationCode) || strings.Contains(err.Error(), "duplicate key value")
 }
 
 func BuildQuery(db squirrel.SelectBuilder, condition *database.CommonCondition) (squirrel.SelectBuilder, error) {
@@ -135,36 +175,57 @@ func BuildQuery(db squirrel.SelectBuilder, condition *database.CommonCondition)
 
 func BuildConditions(db squirrel.SelectBuilder, conditions []database.Condition) (squirrel.SelectBuilder, error) {
 	for _, cond := range conditions {
-		switch strings.ToLower(cond.Op) {
-		case constants.Equal:
-			db = db.Where(squirrel.Eq{cond.Field: cond.Value})
-		case constants.NotEqual:
-			db = db.Where(squirrel.NotEq{cond.Field: cond.Value})
-		case constants.LessThan:
-			db = db.Where(squirrel.Lt{cond.Field: cond.Value})
-		case constants.GreaterThan:
-			db = db.Where(squirrel.Gt{cond.Field: cond.Value})
-		case constants.LessThanOrEqual:
-			db = db.Where(squirrel.LtOrEq{cond.Field: cond.Value})
-		case constants.GreaterThanOrEqual:
-			db = db.Where(squirrel.GtOrEq{cond.Field: cond.Value})
-		case constants.In:
-			db = db.Where(squirrel.Eq{cond.Field: cond.Value})
-		case constants.Like:
-			db = db.Where(squirrel.Like{cond.Field: cond.Value})
-		case constants.NotLike:
-			db = db.Where(squirrel.NotLike{cond.Field: cond.Value})
-		case constants.ILike:
-			db = db.Where(squirrel.ILike{cond.Field: cond.Value})
-		case constants.NotILike:
-			db = db.Where(squirrel.NotILike{cond.Field: cond.Value})
-		default:
-			return db, fmt.Errorf("unsupported operator: %s", cond.Op)
+		pred, err := BuildPredicate(cond)
+		if err != nil {
+			return db, err
 		}
+		db = db.Where(pred)
 	}
 	return db, nil
 }
 
+// BuildPredicates returns the conjunction of conditions
+func BuildPredicates(conditions []database.Condition) (squirrel.And, error) {
+	preds := make(squirrel.And, 0, len(conditions))
+	for _, cond := range conditions {
+		pred, err := BuildPredicate(cond)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+	}
+	return preds, nil
+}
+
+func BuildPredicate(cond database.Condition) (squirrel.Sqlizer, error) {
+	switch strings.ToLower(cond.Op) {
+	case constants.Equal:
+		return squirrel.Eq{cond.Field: cond.Value}, nil
+	case constants.NotEqual:
+		return squirrel.NotEq{cond.Field: cond.Value}, nil
+	case constants.LessThan:
+		return squirrel.Lt{cond.Field: cond.Value}, nil
+	case constants.GreaterThan:
+		return squirrel.Gt{cond.Field: cond.Value}, nil
+	case constants.LessThanOrEqual:
+		return squirrel.LtOrEq{cond.Field: cond.Value}, nil
+	case constants.GreaterThanOrEqual:
+		return squirrel.GtOrEq{cond.Field: cond.Value}, nil
+	case constants.In:
+		return squirrel.Eq{cond.Field: cond.Value}, nil
+	case constants.Like:
+		return squirrel.Like{cond.Field: cond.Value}, nil
+	case constants.NotLike:
+		return squirrel.NotLike{cond.Field: cond.Value}, nil
+	case constants.ILike:
+		return squirrel.ILike{cond.Field: cond.Value}, nil
+	case constants.NotILike:
+		return squirrel.NotILike{cond.Field: cond.Value}, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", cond.Op)
+	}
+}
+
 func BuildSorting(db squirrel.SelectBuilder, sorting []database.Sorting) squirrel.SelectBuilder {
 	for _, sort := range sorting {
 		if sort.Order == constants.Asc {
@@ -187,32 +248,11 @@ func BuildPaging(db squirrel.SelectBuilder, paging *database.Paging) squirrel.Se
 
 func BuildUpdateConditions(db squirrel.UpdateBuilder, conditions []database.Condition) (squirrel.UpdateBuilder, error) {
 	for _, cond := range conditions {
-		switch strings.ToLower(cond.Op) {
-		case constants.Equal:
-			db = db.Where(squirrel.Eq{cond.Field: cond.Value})
-		case constants.NotEqual:
-			db = db.Where(squirrel.NotEq{cond.Field: cond.Value})
-		case constants.LessThan:
-			db = db.Where(squirrel.Lt{cond.Field: cond.Value})
-		case constants.GreaterThan:
-			db = db.Where(squirrel.Gt{cond.Field: cond.Value})
-		case constants.LessThanOrEqual:
-			db = db.Where(squirrel.LtOrEq{cond.Field: cond.Value})
-		case constants.GreaterThanOrEqual:
-			db = db.Where(squirrel.GtOrEq{cond.Field: cond.Value})
-		case constants.In:
-			db = db.Where(squirrel.Eq{cond.Field: cond.Value})
-		case constants.Like:
-			db = db.Where(squirrel.Like{cond.Field: cond.Value})
-		case constants.NotLike:
-			db = db.Where(squirrel.NotLike{cond.Field: cond.Value})
-		case constants.ILike:
-			db = db.Where(squirrel.ILike{cond.Field: cond.Value})
-		case constants.NotILike:
-			db = db.Where(squirrel.NotILike{cond.Field: cond.Value})
-		default:
-			return db, fmt.Errorf("unsupported operator: %s", cond.Op)
+		pred, err := BuildPredicate(cond)
+		if err != nil {
+			return db, err
 		}
+		db = db.Where(pred)
 	}
 	return db, nil
 }
@@ -223,3 +263,15 @@ func getCondition(condition *database.CommonCondition) *database.CommonCondition
 	}
 	return condition
 }
+
+// chunks splits s in slices of at most size elements, sharing the array of s
+func chunks[E any](s []E, size int) [][]E {
+	result := make([][]E, 0, (len(s)+size-1)/size)
+	for size < len(s) {
+		s, result = s[size:], append(result, s[:size:size])
+	}
+	if len(s) > 0 {
+		result = append(result, s)
+	}
+	return result
+}
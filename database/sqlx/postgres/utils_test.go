@@ -0,0 +1,75 @@
+package sqlx_postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+func TestBuildPredicates(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []database.Condition
+		want       string
+		args       []interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "equal",
+			conditions: []database.Condition{{Field: "name", Value: "a", Op: constants.Equal}},
+			want:       "(name = ?)",
+			args:       []interface{}{"a"},
+		},
+		{
+			name:       "is null",
+			conditions: []database.Condition{{Field: "deleted_at", Value: nil, Op: constants.Equal}},
+			want:       "(deleted_at IS NULL)",
+		},
+		{
+			name: "conjunction",
+			conditions: []database.Condition{
+				{Field: "age", Value: 18, Op: constants.GreaterThanOrEqual},
+				{Field: "name", Value: "a%", Op: constants.ILike},
+				{Field: "id", Value: []string{"1", "2"}, Op: constants.In},
+			},
+			want: "(age >= ? AND name ILIKE ? AND id IN (?,?))",
+			args: []interface{}{18, "a%", "1", "2"},
+		},
+		{
+			name:       "operator is case insensitive",
+			conditions: []database.Condition{{Field: "name", Value: "a", Op: "NE"}},
+			want:       "(name <> ?)",
+			args:       []interface{}{"a"},
+		},
+		{
+			name:       "unsupported operator",
+			conditions: []database.Condition{{Field: "name", Value: "a", Op: "between"}},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preds, err := BuildPredicates(tt.conditions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildPredicates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			query, args, err := preds.ToSql()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query != tt.want {
+				t.Errorf("query = %s, want %s", query, tt.want)
+			}
+			if len(args) > 0 || len(tt.args) > 0 {
+				if !reflect.DeepEqual(args, tt.args) {
+					t.Errorf("args = %v, want %v", args, tt.args)
+				}
+			}
+		})
+	}
+}
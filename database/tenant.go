@@ -0,0 +1,21 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+)
+
+// ErrTenantRequired is returned by tenant scoped repositories when the context has no tenant ID
+var ErrTenantRequired = errors.New("tenant id is required")
+
+// NewContextWithTenantID stores the tenant the queries of ctx are scoped to
+func NewContextWithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, constants.ContextKeyTenantID, tenantID)
+}
+
+func GetTenantID(ctx context.Context) string {
+	tenantID, _ := ctx.Value(constants.ContextKeyTenantID).(string)
+	return tenantID
+}
@@ -1,5 +1,7 @@
 package database
 
+import "github.com/dotrongnhan/sharing-package/pkg/constants"
+
 type Pagination[T any] struct {
 	Data []*T  `json:"data"`
 	Meta *Meta `json:"meta"`
@@ -28,11 +30,28 @@ type Condition struct {
 	Op    string // "eq", "ne", "lt", "gt", "lte", "gte", "in", "like", ...
 }
 
+// Join joins Table, optionally aliased, on the On clause. Columns of a joined table are
+// referenced qualified in conditions and sorting: "orders.status".
+type Join struct {
+	Type  string // "inner" or "left"
+	Table string
+	Alias string
+	// On is written in the statement verbatim, without placeholders nor validation, it must
+	// be a constant of the code and never contain user input
+	On string
+}
+
 type CommonCondition struct {
 	Conditions      []Condition
 	Sorting         []Sorting
 	Paging          *Paging
 	IsSkipDeletedAt bool
+	// Preloads are the relations loaded on the results, see Preload
+	Preloads []string
+	// Joins are the tables joined to the repository table, see InnerJoin and LeftJoin
+	Joins []Join
+	// Fields are the columns selected, every column of the entity when empty
+	Fields []string
 }
 
 func NewCommonCondition() *CommonCondition {
@@ -95,3 +114,34 @@ func (cc *CommonCondition) SkipDeletedAt() *CommonCondition {
 	cc.IsSkipDeletedAt = true
 	return cc
 }
+
+// Preload loads the relations declared on the entity fields named by relations, in one
+// query per relation. Nested relations are separated by dots: "Items.Product".
+func (cc *CommonCondition) Preload(relations ...string) *CommonCondition {
+	cc.Preloads = append(cc.Preloads, relations...)
+	return cc
+}
+
+// WithFields selects only fields, which must be columns of the entity. The other fields
+// of the results are left to their zero value.
+func (cc *CommonCondition) WithFields(fields ...string) *CommonCondition {
+	cc.Fields = append(cc.Fields, fields...)
+	return cc
+}
+
+// InnerJoin keeps the rows having a match in table on the on clause, ex: InnerJoin("users", "users.id = orders.user_id").
+// on is written verbatim, see Join.On.
+func (cc *CommonCondition) InnerJoin(table, on string) *CommonCondition {
+	return cc.WithJoin(Join{Type: constants.InnerJoin, Table: table, On: on})
+}
+
+// LeftJoin keeps every row, the columns of table being NULL when there is no match on the on clause.
+// on is written verbatim, see Join.On.
+func (cc *CommonCondition) LeftJoin(table, on string) *CommonCondition {
+	return cc.WithJoin(Join{Type: constants.LeftJoin, Table: table, On: on})
+}
+
+func (cc *CommonCondition) WithJoin(join Join) *CommonCondition {
+	cc.Joins = append(cc.Joins, join)
+	return cc
+}
@@ -33,7 +33,10 @@ func GetColumns(model interface{}) ([]string, error) {
 	for i := 0; i < v.NumField(); i++ {
 		field := t.Field(i)
 		fieldValue := v.Field(i)
-		if fieldValue.Kind() == reflect.Struct && field.Type.Name() == "Base" {
+		if isSkippedField(field) {
+			continue
+		}
+		if isEmbeddedField(field) {
 			// Gọi đệ quy để lấy các trường từ `Base`
 			baseColumns, err := GetColumns(fieldValue.Interface())
 			if err != nil {
@@ -73,10 +76,10 @@ func GetColumnsAndValues(model interface{}) ([]string, []interface{}, error) {
 	for i := 0; i < v.NumField(); i++ {
 		field := t.Field(i)
 		fieldValue := v.Field(i)
-		if field.Tag.Get("omit") != "" && fieldValue.IsZero() {
+		if field.Tag.Get("omit") != "" && fieldValue.IsZero() || isSkippedField(field) {
 			continue
 		}
-		if fieldValue.Kind() == reflect.Struct && field.Type.Name() == "Base" {
+		if isEmbeddedField(field) {
 			baseColumns, baseValues, err := GetColumnsAndValues(fieldValue.Interface())
 			if err != nil {
 				return nil, nil, err
@@ -116,6 +119,21 @@ func GetColumnsAndValuesForMany(models []interface{}) ([]string, [][]interface{}
 	return columns, values, nil
 }
 
+// isSkippedField tells whether field is not a column of the entity table: tagged db:"-",
+// holding a relation or a joined entity
+func isSkippedField(field reflect.StructField) bool {
+	return field.Tag.Get("db") == "-" || field.Tag.Get("relation") != "" || field.Tag.Get("join") != ""
+}
+
+// isEmbeddedField tells whether the columns of field are the ones of its struct: Base or
+// an embedded struct without db tag
+func isEmbeddedField(field reflect.StructField) bool {
+	if field.Type.Kind() != reflect.Struct {
+		return false
+	}
+	return field.Type.Name() == "Base" || field.Anonymous && field.Tag.Get("db") == ""
+}
+
 func GetMetaPagination(total uint64, paging *Paging) *Meta {
 	if total == 0 || paging == nil || paging.Limit == 0 {
 		return &Meta{
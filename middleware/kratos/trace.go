@@ -0,0 +1,68 @@
+package kratos
+
+import (
+	"context"
+
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceServer reads logger.TraceIDHeaderKey from the incoming HTTP headers or gRPC metadata,
+// generating a trace ID when it is missing, stores it in the context under logger.TraceKey
+// and echoes it in the reply headers. The trace ID of an OTel span in the context wins over
+// the header, like in logger.GetTraceIDs, so the logs and the reply use the same one. The operation and the HTTP path are added to the log fields.
+func TraceServer() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var header string
+			tr, ok := transport.FromServerContext(ctx)
+			if ok {
+				header = tr.RequestHeader().Get(logger.TraceIDHeaderKey)
+			}
+			traceID := getTraceID(ctx, header)
+			ctx = context.WithValue(ctx, logger.TraceKey, traceID)
+			if ok {
+				tr.ReplyHeader().Set(logger.TraceIDHeaderKey, traceID)
+				ctx = logger.WithFields(ctx, logger.OperationKey, tr.Operation())
+				if ht, isHTTP := tr.(http.Transporter); isHTTP {
+					ctx = logger.WithFields(ctx, logger.PathKey, ht.Request().URL.Path)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// TraceClient sets logger.TraceIDHeaderKey on outbound HTTP requests and gRPC metadata
+// so the callee logs with the same trace ID
+func TraceClient() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			traceID := getTraceID(ctx, "")
+			ctx = context.WithValue(ctx, logger.TraceKey, traceID)
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				tr.RequestHeader().Set(logger.TraceIDHeaderKey, traceID)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// getTraceID returns the trace ID of the OTel span of ctx, then header, then the trace ID
+// stored in ctx, generating one when none is set
+func getTraceID(ctx context.Context, header string) string {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		return spanContext.TraceID().String()
+	}
+	if header != "" {
+		return header
+	}
+	traceID, _ := logger.GetTraceIDs(ctx)
+	if traceID == "" {
+		traceID = logger.GenerateTraceID()
+	}
+	return traceID
+}
@@ -0,0 +1,128 @@
+package kratos
+
+import (
+	"context"
+	nethttp "net/http"
+	"testing"
+
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+	"github.com/go-kratos/kratos/v2/transport"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type headerCarrier nethttp.Header
+
+func (h headerCarrier) Get(key string) string        { return nethttp.Header(h).Get(key) }
+func (h headerCarrier) Set(key string, value string) { nethttp.Header(h).Set(key, value) }
+func (h headerCarrier) Add(key string, value string) { nethttp.Header(h).Add(key, value) }
+func (h headerCarrier) Values(key string) []string   { return nethttp.Header(h).Values(key) }
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct {
+	request headerCarrier
+	reply   headerCarrier
+}
+
+func newTestTransport() *testTransport {
+	return &testTransport{request: headerCarrier{}, reply: headerCarrier{}}
+}
+
+func (t *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (t *testTransport) Endpoint() string                { return "" }
+func (t *testTransport) Operation() string               { return "/test.Service/Call" }
+func (t *testTransport) RequestHeader() transport.Header { return t.request }
+func (t *testTransport) ReplyHeader() transport.Header   { return t.reply }
+
+const spanTraceID = "0102030405060708090a0b0c0d0e0f10"
+
+func withSpan(ctx context.Context) context.Context {
+	traceID, _ := trace.TraceIDFromHex(spanTraceID)
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+}
+
+func TestTraceServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		span   bool
+		header string
+		want   string
+	}{
+		{name: "uses the span", span: true, header: "header", want: spanTraceID},
+		{name: "uses the header without a span", header: "header", want: "header"},
+		{name: "generates a trace ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTransport()
+			if tt.header != "" {
+				tr.request.Set(logger.TraceIDHeaderKey, tt.header)
+			}
+			ctx := transport.NewServerContext(context.Background(), tr)
+			if tt.span {
+				ctx = withSpan(ctx)
+			}
+			var got string
+			_, err := TraceServer()(func(ctx context.Context, req interface{}) (interface{}, error) {
+				got, _ = logger.GetTraceIDs(ctx)
+				if traceID, _ := ctx.Value(logger.TraceKey).(string); traceID != got {
+					t.Errorf("context trace ID = %s, logged %s", traceID, got)
+				}
+				return nil, nil
+			})(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == "" || (tt.want != "" && got != tt.want) {
+				t.Errorf("trace ID = %q, want %q", got, tt.want)
+			}
+			if reply := tr.reply.Get(logger.TraceIDHeaderKey); reply != got {
+				t.Errorf("reply header = %s, want %s", reply, got)
+			}
+		})
+	}
+}
+
+func TestTraceClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		span    bool
+		traceID string
+		want    string
+	}{
+		{name: "uses the span", span: true, traceID: "context", want: spanTraceID},
+		{name: "uses the context trace ID", traceID: "context", want: "context"},
+		{name: "generates a trace ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTransport()
+			ctx := transport.NewClientContext(context.Background(), tr)
+			if tt.traceID != "" {
+				ctx = context.WithValue(ctx, logger.TraceKey, tt.traceID)
+			}
+			if tt.span {
+				ctx = withSpan(ctx)
+			}
+			_, err := TraceClient()(func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			})(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := tr.request.Get(logger.TraceIDHeaderKey)
+			if got == "" || (tt.want != "" && got != tt.want) {
+				t.Errorf("request header = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dotrongnhan/sharing-package/database"
+	"github.com/dotrongnhan/sharing-package/pkg/constants"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+)
+
+var (
+	// ErrIdempotencyRequestInFlight is returned when a request with the same key is still executing
+	// after IdempotencyConfig.WaitTimeout
+	ErrIdempotencyRequestInFlight = errors.New("request with the same idempotency key is in progress")
+	// ErrIdempotencyKeyReused is returned when a key is replayed with a different input
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+)
+
+// IdempotencyKeyFunc extracts the idempotency key of a request, an empty key disables idempotency
+type IdempotencyKeyFunc func(ctx context.Context, input interface{}) string
+
+// IdempotencyScopeFunc returns the scope the keys are unique in, callers of different scopes
+// never share a record even when they send the same key
+type IdempotencyScopeFunc func(ctx context.Context) []string
+
+// IdempotencyKeyGetter can be implemented by use case inputs carrying their own key
+type IdempotencyKeyGetter interface {
+	GetIdempotencyKey() string
+}
+
+const (
+	defaultIdempotencyWaitTimeout  = 10 * time.Second
+	defaultIdempotencyPollInterval = 100 * time.Millisecond
+	defaultIdempotencyLeaseTTL     = time.Minute
+)
+
+type IdempotencyConfig struct {
+	// KeyFunc defaults to GetIdempotencyKey
+	KeyFunc IdempotencyKeyFunc
+	// ScopeFunc defaults to GetIdempotencyScope
+	ScopeFunc IdempotencyScopeFunc
+	// NewResponse returns a pointer the stored response is decoded into on replay.
+	// When nil, replayed responses are returned as json.RawMessage.
+	NewResponse func() interface{}
+	// WaitTimeout bounds how long a duplicate request waits for the first one to complete, defaults to 10s
+	WaitTimeout time.Duration
+	// PollInterval is the delay between two reads of the record while waiting, defaults to 100ms
+	PollInterval time.Duration
+	// LeaseTTL is the time after which a key left in progress, by a crashed process or a
+	// rolled back transaction, can be claimed again, defaults to 1m. It must be longer than
+	// the use case takes to execute.
+	LeaseTTL time.Duration
+}
+
+// NewContextWithIdempotencyKey stores the idempotency key of the current request in ctx
+func NewContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, constants.ContextKeyIdempotencyKey, key)
+}
+
+// GetIdempotencyKey returns the key from the input when it implements IdempotencyKeyGetter,
+// falling back to the key stored in ctx
+func GetIdempotencyKey(ctx context.Context, input interface{}) string {
+	if getter, ok := input.(IdempotencyKeyGetter); ok {
+		if key := getter.GetIdempotencyKey(); key != "" {
+			return key
+		}
+	}
+	key, _ := ctx.Value(constants.ContextKeyIdempotencyKey).(string)
+	return key
+}
+
+// GetIdempotencyScope scopes the keys to the tenant and the actor of ctx
+func GetIdempotencyScope(ctx context.Context) []string {
+	return []string{database.GetTenantID(ctx), database.GetActorID(ctx)}
+}
+
+// IdempotencyMiddleware replays the stored response of requests already executed with the same key
+// in the same scope. The key is claimed in its own transaction, a duplicate request waits for the
+// first one to complete and replays its response. Place it inside TransactionMiddleware so the
+// response is stored in the use case transaction. When that transaction fails to commit after the
+// middleware returned, the key stays in progress until its lease expires.
+func IdempotencyMiddleware(store database.IdempotencyStore, config *IdempotencyConfig) Middleware {
+	cfg := getIdempotencyConfig(config)
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context, input interface{}) (interface{}, error) {
+			ctxLogger := logger.NewLogger(ctx)
+			key := cfg.KeyFunc(ctx, input)
+			if key == "" {
+				return next(ctx, input)
+			}
+			key = getScopedKey(cfg.ScopeFunc(ctx), key)
+
+			fingerprint, err := getFingerprint(input)
+			if err != nil {
+				ctxLogger.Errorw(logger.MsgKey, "Failed while fingerprint input", logger.ErrorKey, err)
+				return nil, err
+			}
+
+			record, err := claim(ctx, store, key, fingerprint, cfg)
+			if err != nil {
+				if !errors.Is(err, ErrIdempotencyRequestInFlight) {
+					ctxLogger.Errorw(logger.MsgKey, "Failed while claim idempotency key", logger.ErrorKey, err)
+				}
+				return nil, err
+			}
+			if record != nil {
+				return replay(record, fingerprint, cfg.NewResponse)
+			}
+
+			res, err := next(ctx, input)
+			if err == nil {
+				err = complete(ctx, store, key, res)
+			}
+			if err != nil {
+				// the key is claimed on its own, it must be released whatever failed
+				if relErr := store.Release(ctx, key); relErr != nil {
+					ctxLogger.Errorw(logger.MsgKey, "Failed to release idempotency key", logger.ErrorKey, relErr)
+				}
+				return nil, err
+			}
+			return res, nil
+		}
+	}
+}
+
+func getIdempotencyConfig(config *IdempotencyConfig) *IdempotencyConfig {
+	cfg := IdempotencyConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = GetIdempotencyKey
+	}
+	if cfg.ScopeFunc == nil {
+		cfg.ScopeFunc = GetIdempotencyScope
+	}
+	if cfg.WaitTimeout <= 0 {
+		cfg.WaitTimeout = defaultIdempotencyWaitTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultIdempotencyPollInterval
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = defaultIdempotencyLeaseTTL
+	}
+	return &cfg
+}
+
+// getScopedKey returns the key stored for key in scope, encoded as a JSON array so no
+// scope and key pair can collide with another
+func getScopedKey(scope []string, key string) string {
+	b, _ := json.Marshal(append(append(make([]string, 0, len(scope)+1), scope...), key))
+	return string(b)
+}
+
+// claim acquires key, returning a nil record when the request must be executed. When another
+// request holds the key, it waits until that request completes and returns its record, or
+// acquires the key when it has been released or its lease has expired.
+func claim(ctx context.Context, store database.IdempotencyStore, key, fingerprint string, cfg *IdempotencyConfig) (*database.IdempotencyRecord, error) {
+	timer := time.NewTimer(cfg.WaitTimeout)
+	defer timer.Stop()
+	for {
+		record, err := store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case record == nil:
+			_, err = store.Acquire(ctx, key, fingerprint)
+			if err == nil {
+				return nil, nil
+			}
+			if !errors.Is(err, database.ErrIdempotencyKeyExists) {
+				return nil, err
+			}
+		case record.Status == database.IdempotencyStatusInProgress && time.Since(record.UpdatedAt) > cfg.LeaseTTL:
+			reclaimed, err := store.Reclaim(ctx, key, fingerprint, time.Now().Add(-cfg.LeaseTTL))
+			if err != nil {
+				return nil, err
+			}
+			if reclaimed {
+				return nil, nil
+			}
+		case record.Fingerprint != fingerprint || record.Status == database.IdempotencyStatusCompleted:
+			return record, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, ErrIdempotencyRequestInFlight
+		case <-time.After(cfg.PollInterval):
+		}
+	}
+}
+
+// complete stores the response of the request holding key
+func complete(ctx context.Context, store database.IdempotencyStore, key string, res interface{}) error {
+	ctxLogger := logger.NewLogger(ctx)
+	response, err := json.Marshal(res)
+	if err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while marshal response", logger.ErrorKey, err)
+		return err
+	}
+	if err = store.Complete(ctx, key, response); err != nil {
+		ctxLogger.Errorw(logger.MsgKey, "Failed while complete idempotency key", logger.ErrorKey, err)
+		return err
+	}
+	return nil
+}
+
+func replay(record *database.IdempotencyRecord, fingerprint string, newResponse func() interface{}) (interface{}, error) {
+	if record.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if record.Status != database.IdempotencyStatusCompleted {
+		return nil, ErrIdempotencyRequestInFlight
+	}
+	if newResponse == nil {
+		return json.RawMessage(record.Response), nil
+	}
+	res := newResponse()
+	if err := json.Unmarshal(record.Response, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func getFingerprint(input interface{}) (string, error) {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dotrongnhan/sharing-package/database"
+)
+
+type memoryIdempotencyStore struct {
+	mu          sync.Mutex
+	records     map[string]database.IdempotencyRecord
+	completeErr error
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]database.IdempotencyRecord)}
+}
+
+func (s *memoryIdempotencyStore) Get(_ context.Context, key string) (*database.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (s *memoryIdempotencyStore) Acquire(_ context.Context, key string, fingerprint string) (*database.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[key]; ok {
+		return nil, database.ErrIdempotencyKeyExists
+	}
+	record := database.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      database.IdempotencyStatusInProgress,
+		UpdatedAt:   time.Now(),
+	}
+	s.records[key] = record
+	return &record, nil
+}
+
+func (s *memoryIdempotencyStore) Reclaim(_ context.Context, key string, fingerprint string, staleBefore time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok || record.Status != database.IdempotencyStatusInProgress || !record.UpdatedAt.Before(staleBefore) {
+		return false, nil
+	}
+	record.Fingerprint = fingerprint
+	record.UpdatedAt = time.Now()
+	s.records[key] = record
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, key string, response []byte) error {
+	if s.completeErr != nil {
+		return s.completeErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := s.records[key]
+	record.Status = database.IdempotencyStatusCompleted
+	record.Response = response
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+type idempotentInput struct {
+	Key   string `json:"key"`
+	Value int    `json:"value"`
+}
+
+func (i idempotentInput) GetIdempotencyKey() string {
+	return i.Key
+}
+
+func TestIdempotencyMiddlewareWaitsForInFlightRequest(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls int32
+	execute := IdempotencyMiddleware(store, &IdempotencyConfig{PollInterval: time.Millisecond})(
+		func(ctx context.Context, input interface{}) (interface{}, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+				<-release
+			}
+			return map[string]int{"value": input.(idempotentInput).Value}, nil
+		})
+	input := idempotentInput{Key: "key", Value: 1}
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := execute(context.Background(), input)
+		first <- err
+	}()
+	<-started
+	second := make(chan interface{}, 1)
+	go func() {
+		res, err := execute(context.Background(), input)
+		if err != nil {
+			t.Error(err)
+		}
+		second <- res
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+	res := <-second
+	if got := string(res.(json.RawMessage)); got != `{"value":1}` {
+		t.Errorf("replayed %s", got)
+	}
+	if calls != 1 {
+		t.Errorf("executed %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name        string
+		stored      *database.IdempotencyRecord
+		input       idempotentInput
+		err         error
+		completeErr error
+		wantErr     error
+		want        string
+		calls       int32
+		released    bool
+	}{
+		{
+			name:  "executes a new key",
+			input: idempotentInput{Key: "key", Value: 1},
+			want:  `{"value":1}`,
+			calls: 1,
+		},
+		{
+			name: "replays a completed key",
+			stored: &database.IdempotencyRecord{
+				Status:   database.IdempotencyStatusCompleted,
+				Response: []byte(`{"value":2}`),
+			},
+			input: idempotentInput{Key: "key", Value: 1},
+			want:  `{"value":2}`,
+		},
+		{
+			name:    "rejects a key reused with a different input",
+			stored:  &database.IdempotencyRecord{Fingerprint: "other", Status: database.IdempotencyStatusCompleted},
+			input:   idempotentInput{Key: "key", Value: 1},
+			wantErr: ErrIdempotencyKeyReused,
+		},
+		{
+			name:    "times out while the key is in progress",
+			stored:  &database.IdempotencyRecord{Status: database.IdempotencyStatusInProgress, UpdatedAt: time.Now()},
+			input:   idempotentInput{Key: "key", Value: 1},
+			wantErr: ErrIdempotencyRequestInFlight,
+		},
+		{
+			name: "reclaims a key whose lease expired",
+			stored: &database.IdempotencyRecord{
+				Fingerprint: "crashed",
+				Status:      database.IdempotencyStatusInProgress,
+				UpdatedAt:   time.Now().Add(-2 * time.Minute),
+			},
+			input: idempotentInput{Key: "key", Value: 1},
+			want:  `{"value":1}`,
+			calls: 1,
+		},
+		{
+			name:     "releases the key on error",
+			input:    idempotentInput{Key: "key", Value: 1},
+			err:      errFailed,
+			wantErr:  errFailed,
+			calls:    1,
+			released: true,
+		},
+		{
+			name:        "releases the key when the response is not stored",
+			input:       idempotentInput{Key: "key", Value: 1},
+			completeErr: errFailed,
+			wantErr:     errFailed,
+			calls:       1,
+			released:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryIdempotencyStore()
+			store.completeErr = tt.completeErr
+			key := getScopedKey(GetIdempotencyScope(context.Background()), tt.input.Key)
+			if tt.stored != nil {
+				record := *tt.stored
+				if record.Fingerprint == "" {
+					record.Fingerprint, _ = getFingerprint(tt.input)
+				}
+				store.records[key] = record
+			}
+			var calls int32
+			execute := IdempotencyMiddleware(store, &IdempotencyConfig{
+				WaitTimeout:  20 * time.Millisecond,
+				PollInterval: time.Millisecond,
+			})(func(ctx context.Context, input interface{}) (interface{}, error) {
+				calls++
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return map[string]int{"value": input.(idempotentInput).Value}, nil
+			})
+			res, err := execute(context.Background(), tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.calls {
+				t.Errorf("executed %d times, want %d", calls, tt.calls)
+			}
+			if tt.want != "" {
+				got, _ := json.Marshal(res)
+				if string(got) != tt.want {
+					t.Errorf("response = %s, want %s", got, tt.want)
+				}
+			}
+			if tt.released {
+				if record, _ := store.Get(context.Background(), key); record != nil {
+					t.Error("key not released")
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareScopesKeys(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	var calls int32
+	execute := IdempotencyMiddleware(store, nil)(func(ctx context.Context, input interface{}) (interface{}, error) {
+		calls++
+		return database.GetTenantID(ctx), nil
+	})
+	input := idempotentInput{Key: "key", Value: 1}
+	for _, tenant := range []string{"a", "b", "a"} {
+		ctx := database.NewContextWithTenantID(context.Background(), tenant)
+		res, err := execute(ctx, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := json.Marshal(res)
+		if string(got) != `"`+tenant+`"` {
+			t.Errorf("tenant %s got the response %s", tenant, got)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("executed %d times, want once per tenant", calls)
+	}
+}
+
+func TestGetScopedKey(t *testing.T) {
+	tests := []struct {
+		scope []string
+		key   string
+		want  string
+	}{
+		{scope: nil, key: "k", want: `["k"]`},
+		{scope: []string{"t", "u"}, key: "k", want: `["t","u","k"]`},
+		{scope: []string{"t", `u","k`}, key: "k", want: `["t","u\",\"k","k"]`},
+	}
+	for _, tt := range tests {
+		if got := getScopedKey(tt.scope, tt.key); got != tt.want {
+			t.Errorf("getScopedKey(%v, %s) = %s, want %s", tt.scope, tt.key, got, tt.want)
+		}
+	}
+}
@@ -35,7 +35,7 @@ func LockMiddleware(lm *LockManager) Middleware {
 			// Proceed with the next handler
 			res, err := next(ctx, input)
 			if err != nil {
-				ctxLogger.Errorf("Error during execution: %v\n", err)
+				ctxLogger.Errorw(logger.MsgKey, "Error during execution", logger.ErrorKey, err)
 				return nil, err
 			}
 
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+	"time"
+)
+
+// LoggingMiddleware logs the start and the end of the use case name. The input is logged
+// under logger.InputKey after logger.Redact has masked the fields tagged as sensitive.
+func LoggingMiddleware(name string) Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context, input interface{}) (interface{}, error) {
+			// Later logs of the use case are tagged with its name
+			ctx = logger.WithFields(ctx, logger.UseCaseKey, name)
+			ctxLogger := logger.NewLogger(ctx)
+			start := time.Now()
+			ctxLogger.Infow(
+				logger.MsgKey, "Use case started",
+				logger.InputKey, logger.Redact(input),
+			)
+
+			res, err := next(ctx, input)
+			duration := float64(time.Since(start).Microseconds()) / 1000
+			if err != nil {
+				ctxLogger.Errorw(
+					logger.MsgKey, "Use case failed",
+					logger.DurationKey, duration,
+					logger.ErrorKey, err,
+				)
+				return res, err
+			}
+
+			ctxLogger.Infow(
+				logger.MsgKey, "Use case finished",
+				logger.DurationKey, duration,
+			)
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+)
+
+type loginInput struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	t.Cleanup(func() { _ = logger.Configure(logger.Config{}) })
+	tests := []struct {
+		name    string
+		err     error
+		wantEnd map[string]interface{}
+	}{
+		{
+			name:    "finished",
+			wantEnd: map[string]interface{}{logger.MsgKey: "Use case finished", logger.LevelKey: "INFO"},
+		},
+		{
+			name:    "failed",
+			err:     errors.New("failed"),
+			wantEnd: map[string]interface{}{logger.MsgKey: "Use case failed", logger.LevelKey: "ERROR", logger.ErrorKey + ".message": "failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := logger.Configure(logger.Config{Outputs: []io.Writer{&out}}); err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.WithValue(context.Background(), logger.TraceKey, "trace")
+			execute := LoggingMiddleware("Login")(func(ctx context.Context, input interface{}) (interface{}, error) {
+				time.Sleep(5 * time.Millisecond)
+				return nil, tt.err
+			})
+			if _, err := execute(ctx, loginInput{User: "u", Password: "secret"}); !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("logged %d entries, want 2: %s", len(lines), out.String())
+			}
+			var start, end map[string]interface{}
+			if err := json.Unmarshal([]byte(lines[0]), &start); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(lines[1]), &end); err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range []map[string]interface{}{start, end} {
+				if entry[logger.TraceKey] != "trace" || entry[logger.UseCaseKey] != "Login" {
+					t.Errorf("entry %v misses the trace ID or the use case", entry)
+				}
+			}
+			if start[logger.MsgKey] != "Use case started" {
+				t.Errorf("start msg = %v", start[logger.MsgKey])
+			}
+			input, _ := start[logger.InputKey].(map[string]interface{})
+			if input["user"] != "u" || input["password"] != logger.RedactedValue {
+				t.Errorf("input = %v, want the password redacted", start[logger.InputKey])
+			}
+			for key, want := range tt.wantEnd {
+				if end[key] != want {
+					t.Errorf("end %s = %v, want %v", key, end[key], want)
+				}
+			}
+			if duration, _ := end[logger.DurationKey].(float64); duration < 5 {
+				t.Errorf("duration = %v, want at least 5ms", end[logger.DurationKey])
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+	"github.com/dotrongnhan/sharing-package/pkg/metrics"
+	"time"
+)
+
+// MetricsMiddleware records the executions, errors and latency of the use case name
+func MetricsMiddleware(name string) Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context, input interface{}) (interface{}, error) {
+			start := time.Now()
+			res, err := next(ctx, input)
+
+			metrics.UseCaseRequests.WithLabelValues(name).Inc()
+			metrics.UseCaseDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+			if err != nil {
+				metrics.UseCaseErrors.WithLabelValues(name, metrics.ErrorClass(err)).Inc()
+			}
+			return res, err
+		}
+	}
+}
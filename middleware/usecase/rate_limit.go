@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+	"time"
+)
+
+type RateLimitAlgorithm string
+
+const (
+	// TokenBucket refills Limit tokens per Period and allows bursts up to Burst
+	TokenBucket RateLimitAlgorithm = "token_bucket"
+	// SlidingWindow allows at most Limit requests in any window of Period
+	SlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+type RateLimit struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Period    time.Duration
+	// Burst is the token bucket capacity, defaults to Limit
+	Burst int
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps the limiter state. Distributed implementations (Redis, ...)
+// must apply the algorithm atomically for a key.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit) (*RateLimitResult, error)
+}
+
+// RateLimitKeyFunc returns the bucket a request is counted against, an empty key disables limiting
+type RateLimitKeyFunc func(ctx context.Context, input interface{}) string
+
+// GlobalRateLimitKey is the bucket of every request when RateLimitMiddleware has no key func
+const GlobalRateLimitKey = "global"
+
+type RateLimitError struct {
+	Key        string
+	Limit      int
+	Period     time.Duration
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %q: %d requests per %s, retry after %s", e.Key, e.Limit, e.Period, e.RetryAfter)
+}
+
+func (e *RateLimitError) ErrorClass() string {
+	return "rate_limited"
+}
+
+// RateLimitMiddleware rejects requests over limit with a *RateLimitError.
+// Store failures are logged and the request is let through. A nil keyFunc counts every
+// request against GlobalRateLimitKey.
+func RateLimitMiddleware(store RateLimitStore, limit RateLimit, keyFunc RateLimitKeyFunc) Middleware {
+	if keyFunc == nil {
+		keyFunc = func(context.Context, interface{}) string {
+			return GlobalRateLimitKey
+		}
+	}
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context, input interface{}) (interface{}, error) {
+			ctxLogger := logger.NewLogger(ctx)
+			key := keyFunc(ctx, input)
+			if key == "" {
+				return next(ctx, input)
+			}
+
+			res, err := store.Take(ctx, key, limit)
+			if err != nil {
+				ctxLogger.Errorw(logger.MsgKey, "Failed while take rate limit token", logger.ErrorKey, err)
+				return next(ctx, input)
+			}
+			if !res.Allowed {
+				ctxLogger.Warnf("Rate limit exceeded for %s, retry after %s", key, res.RetryAfter)
+				return nil, &RateLimitError{
+					Key:        key,
+					Limit:      limit.Limit,
+					Period:     limit.Period,
+					RetryAfter: res.RetryAfter,
+				}
+			}
+
+			return next(ctx, input)
+		}
+	}
+}
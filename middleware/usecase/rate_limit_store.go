@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const rateLimitSweepInterval = time.Minute
+
+type rateLimitEntry struct {
+	// token bucket state
+	tokens float64
+	last   time.Time
+	// sliding window state, oldest first
+	hits []time.Time
+
+	// ttl is the idle time after which the entry is back to its initial state
+	ttl time.Duration
+}
+
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryRateLimitStore creates a RateLimitStore local to the process
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		entries: make(map[string]*rateLimitEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit) (*RateLimitResult, error) {
+	if limit.Limit <= 0 || limit.Period <= 0 {
+		return nil, fmt.Errorf("invalid rate limit: %d per %s", limit.Limit, limit.Period)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &rateLimitEntry{
+			tokens: float64(getBurst(limit)),
+			last:   now,
+		}
+		s.entries[key] = entry
+	}
+	entry.ttl = limit.Period * time.Duration(math.Max(1, math.Ceil(float64(getBurst(limit))/float64(limit.Limit))))
+
+	switch limit.Algorithm {
+	case TokenBucket, "":
+		return takeTokenBucket(entry, limit, now), nil
+	case SlidingWindow:
+		return takeSlidingWindow(entry, limit, now), nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit algorithm: %s", limit.Algorithm)
+	}
+}
+
+func takeTokenBucket(entry *rateLimitEntry, limit RateLimit, now time.Time) *RateLimitResult {
+	rate := float64(limit.Limit) / limit.Period.Seconds()
+	elapsed := now.Sub(entry.last).Seconds()
+	entry.tokens = math.Min(float64(getBurst(limit)), entry.tokens+elapsed*rate)
+	entry.last = now
+
+	if entry.tokens < 1 {
+		wait := (1 - entry.tokens) / rate
+		return &RateLimitResult{
+			Allowed:    false,
+			RetryAfter: time.Duration(wait * float64(time.Second)),
+		}
+	}
+	entry.tokens--
+	return &RateLimitResult{
+		Allowed:   true,
+		Remaining: int(entry.tokens),
+	}
+}
+
+func takeSlidingWindow(entry *rateLimitEntry, limit RateLimit, now time.Time) *RateLimitResult {
+	windowStart := now.Add(-limit.Period)
+	i := 0
+	for i < len(entry.hits) && !entry.hits[i].After(windowStart) {
+		i++
+	}
+	entry.hits = entry.hits[i:]
+	entry.last = now
+
+	if len(entry.hits) >= limit.Limit {
+		oldest := entry.hits[len(entry.hits)-limit.Limit]
+		return &RateLimitResult{
+			Allowed:    false,
+			RetryAfter: oldest.Add(limit.Period).Sub(now),
+		}
+	}
+	entry.hits = append(entry.hits, now)
+	return &RateLimitResult{
+		Allowed:   true,
+		Remaining: limit.Limit - len(entry.hits),
+	}
+}
+
+// sweep drops the keys which have been idle long enough to be reset
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if now.Sub(entry.last) > entry.ttl {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func getBurst(limit RateLimit) int {
+	if limit.Burst > 0 {
+		return limit.Burst
+	}
+	return limit.Limit
+}
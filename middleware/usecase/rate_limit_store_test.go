@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	type step struct {
+		at        time.Duration
+		allowed   bool
+		remaining int
+		retry     time.Duration
+	}
+	tests := []struct {
+		name  string
+		limit RateLimit
+		steps []step
+	}{
+		{
+			name:  "token bucket refills over time",
+			limit: RateLimit{Algorithm: TokenBucket, Limit: 2, Period: time.Second},
+			steps: []step{
+				{at: 0, allowed: true, remaining: 1},
+				{at: 0, allowed: true, remaining: 0},
+				{at: 0, allowed: false, retry: 500 * time.Millisecond},
+				{at: 500 * time.Millisecond, allowed: true, remaining: 0},
+			},
+		},
+		{
+			name:  "token bucket burst",
+			limit: RateLimit{Limit: 1, Period: time.Second, Burst: 3},
+			steps: []step{
+				{at: 0, allowed: true, remaining: 2},
+				{at: 0, allowed: true, remaining: 1},
+				{at: 0, allowed: true, remaining: 0},
+				{at: 0, allowed: false, retry: time.Second},
+			},
+		},
+		{
+			name:  "sliding window",
+			limit: RateLimit{Algorithm: SlidingWindow, Limit: 2, Period: time.Second},
+			steps: []step{
+				{at: 0, allowed: true, remaining: 1},
+				{at: 400 * time.Millisecond, allowed: true, remaining: 0},
+				{at: 600 * time.Millisecond, allowed: false, retry: 400 * time.Millisecond},
+				{at: time.Second + time.Millisecond, allowed: true, remaining: 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			now := start
+			store := NewMemoryRateLimitStore()
+			store.now = func() time.Time { return now }
+			for i, step := range tt.steps {
+				now = start.Add(step.at)
+				res, err := store.Take(context.Background(), "key", tt.limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.Allowed != step.allowed || res.Remaining != step.remaining || res.RetryAfter != step.retry {
+					t.Errorf("step %d: Take() = %+v, want allowed %v, remaining %d, retry after %s",
+						i, res, step.allowed, step.remaining, step.retry)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimitStoreKeys(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Limit: 1, Period: time.Minute}
+	for _, key := range []string{"a", "b"} {
+		res, err := store.Take(context.Background(), key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed {
+			t.Errorf("key %s limited by another key", key)
+		}
+	}
+}
+
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	limit := RateLimit{Limit: 1, Period: time.Second}
+	if _, err := store.Take(context.Background(), "idle", limit); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(rateLimitSweepInterval)
+	if _, err := store.Take(context.Background(), "active", limit); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.entries["idle"]; ok {
+		t.Error("idle key not swept")
+	}
+}
+
+func TestMemoryRateLimitStoreInvalidLimit(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	tests := []RateLimit{
+		{Limit: 0, Period: time.Second},
+		{Limit: 1, Period: 0},
+		{Algorithm: "leaky_bucket", Limit: 1, Period: time.Second},
+	}
+	for _, limit := range tests {
+		if _, err := store.Take(context.Background(), "key", limit); err == nil {
+			t.Errorf("Take(%+v) accepted an invalid limit", limit)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimitMiddlewareWithoutKeyFunc(t *testing.T) {
+	limit := RateLimit{Algorithm: SlidingWindow, Limit: 1, Period: time.Minute}
+	execute := RateLimitMiddleware(NewMemoryRateLimitStore(), limit, nil)(
+		func(ctx context.Context, input interface{}) (interface{}, error) {
+			return input, nil
+		})
+	if _, err := execute(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	var rateLimitErr *RateLimitError
+	if _, err := execute(context.Background(), 2); !errors.As(err, &rateLimitErr) || rateLimitErr.Key != GlobalRateLimitKey {
+		t.Errorf("second request = %v, want a rate limit error on %s", err, GlobalRateLimitKey)
+	}
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const tracerName = "github.com/dotrongnhan/sharing-package/middleware/usecase"
+
+// TracingMiddleware runs the use case name inside an OpenTelemetry span,
+// repositories called by the use case create child spans of it
+func TracingMiddleware(name string) Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context, input interface{}) (interface{}, error) {
+			ctx, span := otel.Tracer(tracerName).Start(ctx, name)
+			defer span.End()
+			span.SetAttributes(attribute.String("usecase.name", name))
+
+			res, err := next(ctx, input)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return res, err
+			}
+			return res, nil
+		}
+	}
+}
@@ -23,13 +23,13 @@ func TransactionMiddleware(tm database.TransactionManager) Middleware {
 			res, err := next(ctx, input)
 			if err != nil {
 				if rbErr := tm.RollbackTransaction(ctx); rbErr != nil {
-					ctxLogger.Errorf("Failed to rollback transaction: %v\n", rbErr)
+					ctxLogger.Errorw(logger.MsgKey, "Failed to rollback transaction", logger.ErrorKey, rbErr)
 				}
 				return nil, err
 			}
 
 			if err = tm.CommitTransaction(ctx); err != nil {
-				ctxLogger.Errorf("Failed to commit transaction: %v\n", err)
+				ctxLogger.Errorw(logger.MsgKey, "Failed to commit transaction", logger.ErrorKey, err)
 				return nil, err
 			}
 
@@ -0,0 +1,3 @@
+package constants
+
+const ContextKeyIdempotencyKey = "context_idempotency_key"
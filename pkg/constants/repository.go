@@ -32,4 +32,32 @@ const (
 	Desc = "desc"
 )
 
+const (
+	// InnerJoin join type
+	InnerJoin = "inner"
+	// LeftJoin join type
+	LeftJoin = "left"
+)
+
+const (
+	// Count aggregate
+	Count = "count"
+	// Sum aggregate
+	Sum = "sum"
+	// Avg aggregate
+	Avg = "avg"
+	// Min aggregate
+	Min = "min"
+	// Max aggregate
+	Max = "max"
+)
+
 const ContextKeyDBTransaction = "context_db_transaction"
+
+const ContextKeyQueryScope = "context_query_scope"
+
+const ContextKeyDBPrimary = "context_db_primary"
+
+const ContextKeyTenantID = "context_tenant_id"
+
+const ContextKeyActorID = "context_actor_id"
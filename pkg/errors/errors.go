@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+const maxStackDepth = 32
+
+// Error is an error recording the stack trace of the place it was created at
+type Error struct {
+	msg   string
+	cause error
+	stack []uintptr
+}
+
+// New returns an error with message and the current stack trace
+func New(message string) error {
+	return &Error{msg: message, stack: callers()}
+}
+
+// Errorf formats like fmt.Errorf, %w verbs are available through Unwrap. With several %w,
+// the cause is the fmt.Errorf error itself so errors.Is and errors.As reach every one of them.
+func Errorf(format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	var cause error
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		cause = wrapped.Unwrap()
+	case interface{ Unwrap() []error }:
+		cause = err
+	}
+	return &Error{msg: err.Error(), cause: cause, stack: callers()}
+}
+
+// Wrap annotates err with message and the current stack trace, it returns nil when err is nil
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{msg: message + ": " + err.Error(), cause: err, stack: callers()}
+}
+
+// WithStack records the current stack trace on err unless an error of its chain already has one
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+	var withStack *Error
+	if errors.As(err, &withStack) {
+		return err
+	}
+	return &Error{msg: err.Error(), cause: err, stack: callers()}
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// StackTrace returns the frames of the stack trace as "function file:line", innermost first
+func (e *Error) StackTrace() []string {
+	frames := runtime.CallersFrames(e.stack)
+	trace := make([]string, 0, len(e.stack))
+	for {
+		frame, more := frames.Next()
+		trace = append(trace, frame.Function+" "+frame.File+":"+strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return trace
+}
+
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	// skip runtime.Callers, callers and the constructor
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
@@ -0,0 +1,41 @@
+package errors
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestErrorf(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		targets []error
+		cause   bool
+	}{
+		{name: "no wrapped error", err: Errorf("failed: %d", 1)},
+		{name: "single wrapped error", err: Errorf("read: %w", io.EOF), targets: []error{io.EOF}, cause: true},
+		{
+			name:    "several wrapped errors",
+			err:     Errorf("read: %w, close: %w", io.EOF, os.ErrClosed),
+			targets: []error{io.EOF, os.ErrClosed},
+			cause:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, target := range tt.targets {
+				if !errors.Is(tt.err, target) {
+					t.Errorf("errors.Is(%v, %v) = false", tt.err, target)
+				}
+			}
+			if cause := errors.Unwrap(tt.err); (cause != nil) != tt.cause {
+				t.Errorf("Unwrap() = %v, want cause %v", cause, tt.cause)
+			}
+			if len(tt.err.(*Error).StackTrace()) == 0 {
+				t.Error("no stack trace")
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	kratosLogPackage = "github.com/go-kratos/kratos/v2/log"
+	maxCallerFrames  = 32
+)
+
+// DefaultCallerAnchors are used when CallerConfig.Anchors is empty
+var DefaultCallerAnchors = []string{"go/src/backend/"}
+
+var (
+	loggerPackage = getFuncPackage(runtime.FuncForPC(reflect.ValueOf(NewLogger).Pointer()).Name())
+
+	// modulePath is the main module of the binary, paths are relative to it when built with -trimpath
+	modulePath = sync.OnceValue(func() string {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return ""
+		}
+		return info.Main.Path
+	})
+	workingDir = sync.OnceValue(func() string {
+		dir, err := filepath.Abs("")
+		if err != nil {
+			return ""
+		}
+		return dir
+	})
+)
+
+type CallerConfig struct {
+	// Anchors are path fragments the caller file is trimmed after, the first match wins.
+	// Defaults to DefaultCallerAnchors.
+	Anchors []string
+	// FunctionName appends the calling function to the caller field
+	FunctionName bool
+	// SkipPackages are the import paths of logging helpers, their frames are skipped
+	// like the ones of this package and of the Kratos log package
+	SkipPackages []string
+}
+
+// getCaller returns the first frame above skip which is not in a skipped package and its pc,
+// skip 0 being getCaller itself
+func (s *settings) getCaller(skip int) (runtime.Frame, uintptr) {
+	var pcs [maxCallerFrames]uintptr
+	n := runtime.Callers(skip+1, pcs[:])
+	for i := 0; i < n; i++ {
+		// a pc expands to several frames when functions are inlined
+		frames := runtime.CallersFrames(pcs[i : i+1])
+		for {
+			frame, more := frames.Next()
+			if !s.isSkippedPackage(getFuncPackage(frame.Function)) {
+				return frame, pcs[i]
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return runtime.Frame{}, 0
+}
+
+func (s *settings) isSkippedPackage(pkg string) bool {
+	if pkg == loggerPackage || pkg == kratosLogPackage {
+		return true
+	}
+	if s.config.Caller == nil {
+		return false
+	}
+	for _, skipped := range s.config.Caller.SkipPackages {
+		if pkg == skipped {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *settings) formatCaller(frame runtime.Frame) string {
+	if frame.File == "" {
+		return "unknown"
+	}
+	caller := s.trimPath(frame.File) + ":" + strconv.Itoa(frame.Line)
+	if s.config.Caller != nil && s.config.Caller.FunctionName {
+		caller += " " + getShortFuncName(frame.Function)
+	}
+	return caller
+}
+
+// trimPath returns file relative to the project, the result is cached per file
+func (s *settings) trimPath(file string) string {
+	if path, ok := s.callerPaths.Load(file); ok {
+		return path.(string)
+	}
+	path := s.resolvePath(file)
+	s.callerPaths.Store(file, path)
+	return path
+}
+
+func (s *settings) resolvePath(file string) string {
+	// --- Phương pháp 1: Ưu tiên Production, cắt sau anchor (mặc định "go/src/backend/") ---
+	anchors := DefaultCallerAnchors
+	if s.config.Caller != nil && len(s.config.Caller.Anchors) > 0 {
+		anchors = s.config.Caller.Anchors
+	}
+	for _, anchor := range anchors {
+		if idx := strings.Index(file, anchor); idx != -1 {
+			return file[idx+len(anchor):]
+		}
+	}
+
+	// --- Phương pháp 2: Build với -trimpath, đường dẫn bắt đầu bằng module path ---
+	if module := modulePath(); module != "" && strings.HasPrefix(file, module+"/") {
+		return file[len(module)+1:]
+	}
+
+	// --- Phương pháp 3: Thử kiểu Local, tương đối với thư mục làm việc ---
+	if rootPath := workingDir(); rootPath != "" {
+		relativePath, err := filepath.Rel(rootPath, file)
+		if err == nil && !strings.HasPrefix(relativePath, "..") {
+			return relativePath
+		}
+	}
+
+	// --- Phương pháp 4: Fallback, trả về tên file ---
+	return filepath.Base(file)
+}
+
+// getFuncPackage returns the import path of a function name:
+// github.com/org/repo/pkg.(*Type).Method -> github.com/org/repo/pkg
+func getFuncPackage(name string) string {
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot != -1 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
+
+// getShortFuncName trims the directories of the package: pkg.(*Type).Method
+func getShortFuncName(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
@@ -0,0 +1,75 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/dotrongnhan/sharing-package/pkg/logger"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// line returns the line it is called from
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l
+}
+
+var wrapperLine int
+
+// logWrapped is a logging helper of the application, it is reported as the caller
+func logWrapped(ctx context.Context, msg string) {
+	logger.NewLogger(ctx).Info(msg)
+	wrapperLine = line() - 1
+}
+
+func TestCaller(t *testing.T) {
+	t.Cleanup(func() { _ = logger.Configure(logger.Config{}) })
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		log  func() int
+	}{
+		{name: "NewLogger", log: func() int {
+			logger.NewLogger(ctx).Info("hello")
+			return line() - 1
+		}},
+		{name: "NewLogger with keyvals", log: func() int {
+			logger.NewLogger(ctx).Infow(logger.MsgKey, "hello", "k", "v")
+			return line() - 1
+		}},
+		{name: "NewLoggerWith", log: func() int {
+			logger.NewLoggerWith(ctx, "k", "v").Infof("hello %s", "world")
+			return line() - 1
+		}},
+		{name: "Helper", log: func() int {
+			helper := log.NewHelper(logger.NewJSONLogger("", 3))
+			helper.Info("hello")
+			return line() - 1
+		}},
+		{name: "wrapper function", log: func() int {
+			logWrapped(ctx, "hello")
+			return wrapperLine
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := logger.Configure(logger.Config{Outputs: []io.Writer{&out}}); err != nil {
+				t.Fatal(err)
+			}
+			want := "caller_test.go:" + strconv.Itoa(tt.log())
+			var entry map[string]interface{}
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry[logger.CallerKey] != want {
+				t.Errorf("caller = %v, want %s", entry[logger.CallerKey], want)
+			}
+		})
+	}
+}
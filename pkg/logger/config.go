@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type Config struct {
+	// Level is the minimum level written, defaults to log.LevelDebug when nil
+	Level *log.Level
+	// PackageLevels overrides Level for the packages whose import path starts with the key,
+	// the longest matching prefix wins
+	PackageLevels map[string]log.Level
+	// Outputs receive every entry, defaults to os.Stdout
+	Outputs []io.Writer
+	// Rotation adds a file output rotated by size and/or time
+	Rotation *RotationConfig
+	// Async buffers the entries and writes them from a background goroutine
+	Async *AsyncConfig
+	// Sampling drops repetitive messages
+	Sampling *SamplingConfig
+	// Redaction masks the sensitive values before they are written, defaults to DefaultRedactionConfig
+	Redaction *RedactionConfig
+	// Caller controls how the caller field is resolved
+	Caller *CallerConfig
+	// Encoder formats the entries, defaults to NewJSONEncoder. NewECSEncoder, NewGCPEncoder,
+	// NewLogfmtEncoder and NewConsoleEncoder are available.
+	Encoder Encoder
+}
+
+type settings struct {
+	config   Config
+	level    log.Level
+	writer   io.Writer
+	sampler  *sampler
+	redactor *redactor
+	encoder  Encoder
+	closers  []io.Closer
+	// callerPaths caches the trimmed path of the caller files
+	callerPaths sync.Map
+}
+
+var current atomic.Pointer[settings]
+
+func init() {
+	redactor, _ := newRedactor(DefaultRedactionConfig())
+	current.Store(&settings{
+		level:    log.LevelDebug,
+		writer:   os.Stdout,
+		redactor: redactor,
+		encoder:  NewJSONEncoder(),
+	})
+}
+
+// Configure applies cfg to every logger created by NewLogger and NewLoggerWith. It is meant
+// to be called once at startup, the outputs of a previous configuration are closed.
+func Configure(cfg Config) error {
+	redaction := DefaultRedactionConfig()
+	if cfg.Redaction != nil {
+		redaction = *cfg.Redaction
+	}
+	redactor, err := newRedactor(redaction)
+	if err != nil {
+		return err
+	}
+
+	outputs := cfg.Outputs
+	var closers []io.Closer
+	if cfg.Rotation != nil {
+		file, err := NewRotatingFile(*cfg.Rotation)
+		if err != nil {
+			return err
+		}
+		outputs = append(outputs[:len(outputs):len(outputs)], file)
+		closers = append(closers, file)
+	}
+
+	var writer io.Writer
+	switch len(outputs) {
+	case 0:
+		writer = os.Stdout
+	case 1:
+		writer = outputs[0]
+	default:
+		writer = io.MultiWriter(outputs...)
+	}
+	if cfg.Async != nil {
+		async := NewAsyncWriter(writer, *cfg.Async)
+		writer = async
+		// the async writer is flushed before the files are closed
+		closers = append([]io.Closer{async}, closers...)
+	}
+
+	s := &settings{
+		config:   cfg,
+		level:    log.LevelDebug,
+		writer:   writer,
+		redactor: redactor,
+		encoder:  cfg.Encoder,
+		closers:  closers,
+	}
+	if s.encoder == nil {
+		s.encoder = NewJSONEncoder()
+	}
+	if cfg.Level != nil {
+		s.level = *cfg.Level
+	}
+	if cfg.Sampling != nil {
+		s.sampler = newSampler(*cfg.Sampling)
+	}
+
+	previous := current.Swap(s)
+	return previous.close()
+}
+
+// Close flushes the buffered entries and closes the outputs opened by Configure
+func Close() error {
+	return current.Load().close()
+}
+
+func getSettings() *settings {
+	return current.Load()
+}
+
+func (s *settings) close() error {
+	var errs []error
+	for _, c := range s.closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// enabled reports whether an entry of level logged from pc should be written
+func (s *settings) enabled(level log.Level, pc uintptr) bool {
+	if len(s.config.PackageLevels) > 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		return level >= s.minLevel(getFuncPackage(frame.Function))
+	}
+	return level >= s.level
+}
+
+// minLevel returns the minimum level of pkg
+func (s *settings) minLevel(pkg string) log.Level {
+	level := s.level
+	matched := -1
+	for prefix, l := range s.config.PackageLevels {
+		if len(prefix) > matched && (pkg == prefix || strings.HasPrefix(pkg, prefix+"/")) {
+			level = l
+			matched = len(prefix)
+		}
+	}
+	return level
+}
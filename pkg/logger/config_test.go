@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type recordLogger struct {
+	level   log.Level
+	keyvals []interface{}
+}
+
+func (l *recordLogger) Log(level log.Level, keyvals ...interface{}) error {
+	l.level, l.keyvals = level, keyvals
+	return nil
+}
+
+func TestConfigureLevel(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Config{}) })
+	warn := log.LevelWarn
+	tests := []struct {
+		name  string
+		level *log.Level
+		want  []string
+	}{
+		{name: "defaults to debug", want: []string{"debug", "info", "warn"}},
+		{name: "warn", level: &warn, want: []string{"warn"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Configure(Config{Level: tt.level, Outputs: []io.Writer{&out}}); err != nil {
+				t.Fatal(err)
+			}
+			logger := NewLogger(context.Background())
+			logger.Debug("debug")
+			logger.Info("info")
+			logger.Warn("warn")
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				for _, msg := range []string{"debug", "info", "warn"} {
+					if strings.Contains(line, `"msg":"`+msg+`"`) {
+						got = append(got, msg)
+					}
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("written %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLoggerForwardsToLogger(t *testing.T) {
+	var out bytes.Buffer
+	next := &recordLogger{}
+	logger := NewJSONLogger("trace", defaultCallerDepth)
+	logger.Logger = next
+	logger.Output = &out
+	if err := logger.Log(log.LevelInfo, MsgKey, "hello", "password", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("entry written to Output: %s", out.String())
+	}
+	if next.level != log.LevelInfo {
+		t.Errorf("level = %v, want info", next.level)
+	}
+	fields := make(map[string]interface{})
+	for i := 0; i < len(next.keyvals)-1; i += 2 {
+		fields[next.keyvals[i].(string)] = next.keyvals[i+1]
+	}
+	if fields[TraceKey] != "trace" || fields[MsgKey] != "hello" {
+		t.Errorf("keyvals = %v", next.keyvals)
+	}
+	if fields["password"] == "secret" {
+		t.Error("password forwarded without redaction")
+	}
+}
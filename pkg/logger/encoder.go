@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	ecsVersion   = "8.11.0"
+	gcpTraceKey  = "logging.googleapis.com/trace"
+	gcpSpanKey   = "logging.googleapis.com/spanId"
+	gcpSourceKey = "logging.googleapis.com/sourceLocation"
+)
+
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Entry is a log entry once its keyvals have been redacted, ready to be encoded
+type Entry struct {
+	Time    time.Time
+	Level   log.Level
+	TraceID string
+	SpanID  string
+	Caller  string
+	Message string
+	Fields  []Field
+}
+
+// add appends a field, a key already present is overridden in place
+func (e *Entry) add(key string, value interface{}) {
+	for i := range e.Fields {
+		if e.Fields[i].Key == key {
+			e.Fields[i].Value = value
+			return
+		}
+	}
+	e.Fields = append(e.Fields, Field{Key: key, Value: value})
+}
+
+// keyvals returns the fields of entry but its time and level as Kratos keyvals
+func (e *Entry) keyvals() []interface{} {
+	keyvals := make([]interface{}, 0, 2*len(e.Fields)+8)
+	keyvals = append(keyvals, TraceKey, e.TraceID)
+	if e.SpanID != "" {
+		keyvals = append(keyvals, SpanKey, e.SpanID)
+	}
+	keyvals = append(keyvals, CallerKey, e.Caller, MsgKey, e.Message)
+	for _, field := range e.Fields {
+		keyvals = append(keyvals, field.Key, field.Value)
+	}
+	return keyvals
+}
+
+type Encoder interface {
+	Encode(entry *Entry) ([]byte, error)
+}
+
+// JSONEncoder writes an entry as a JSON object, the keys and the formatting of the
+// fixed fields can be changed to match the log backend
+type JSONEncoder struct {
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+	TraceKey   string
+	SpanKey    string
+	CallerKey  string
+	// CallerLineKey, when set, receives the line of the caller, CallerKey keeping the file only
+	CallerLineKey string
+	// CallerFunctionKey, when set along with CallerLineKey, receives the function of the caller
+	CallerFunctionKey string
+	// TimeFormat is a time layout, defaults to time.RFC3339Nano
+	TimeFormat string
+	// OmitEmptyTrace drops TraceKey when the entry has no trace ID
+	OmitEmptyTrace bool
+	EncodeLevel    func(level log.Level) interface{}
+	EncodeTrace    func(traceID string) interface{}
+	EncodeCaller   func(caller string) interface{}
+	// StaticFields are added to every entry
+	StaticFields map[string]interface{}
+}
+
+// NewJSONEncoder returns the encoder used by default, keyed with TimeKey, LevelKey,
+// TraceKey, SpanKey, CallerKey and MsgKey
+func NewJSONEncoder() *JSONEncoder {
+	return &JSONEncoder{
+		TimeKey:    TimeKey,
+		LevelKey:   LevelKey,
+		MessageKey: MsgKey,
+		TraceKey:   TraceKey,
+		SpanKey:    SpanKey,
+		CallerKey:  CallerKey,
+		TimeFormat: time.RFC3339Nano,
+	}
+}
+
+// NewECSEncoder returns an encoder following the Elastic Common Schema
+func NewECSEncoder() *JSONEncoder {
+	return &JSONEncoder{
+		TimeKey:           "@timestamp",
+		LevelKey:          "log.level",
+		MessageKey:        "message",
+		TraceKey:          "trace.id",
+		SpanKey:           "span.id",
+		CallerKey:         "log.origin.file.name",
+		CallerLineKey:     "log.origin.file.line",
+		CallerFunctionKey: "log.origin.function",
+		TimeFormat:        time.RFC3339Nano,
+		OmitEmptyTrace:    true,
+		EncodeLevel: func(level log.Level) interface{} {
+			return strings.ToLower(level.String())
+		},
+		StaticFields: map[string]interface{}{
+			"ecs.version": ecsVersion,
+		},
+	}
+}
+
+// NewGCPEncoder returns an encoder following the Google Cloud Logging structured format,
+// traces are linked to Cloud Trace when projectID is set
+func NewGCPEncoder(projectID string) *JSONEncoder {
+	return &JSONEncoder{
+		TimeKey:        "timestamp",
+		LevelKey:       "severity",
+		MessageKey:     "message",
+		TraceKey:       gcpTraceKey,
+		SpanKey:        gcpSpanKey,
+		CallerKey:      gcpSourceKey,
+		TimeFormat:     time.RFC3339Nano,
+		OmitEmptyTrace: true,
+		EncodeLevel: func(level log.Level) interface{} {
+			switch level {
+			case log.LevelWarn:
+				return "WARNING"
+			case log.LevelFatal:
+				return "CRITICAL"
+			default:
+				return level.String()
+			}
+		},
+		EncodeTrace: func(traceID string) interface{} {
+			if projectID == "" {
+				return traceID
+			}
+			return "projects/" + projectID + "/traces/" + traceID
+		},
+		EncodeCaller: func(caller string) interface{} {
+			file, line, function := splitCaller(caller)
+			location := map[string]interface{}{
+				"file": file,
+				"line": line,
+			}
+			if function != "" {
+				location["function"] = function
+			}
+			return location
+		},
+	}
+}
+
+func (e *JSONEncoder) Encode(entry *Entry) ([]byte, error) {
+	entryMap := make(map[string]interface{}, len(e.StaticFields)+len(entry.Fields)+6)
+	for k, v := range e.StaticFields {
+		entryMap[k] = v
+	}
+
+	timeFormat := e.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+	entryMap[e.TimeKey] = entry.Time.Format(timeFormat)
+	if e.EncodeLevel != nil {
+		entryMap[e.LevelKey] = e.EncodeLevel(entry.Level)
+	} else {
+		entryMap[e.LevelKey] = entry.Level.String()
+	}
+	if entry.TraceID != "" || !e.OmitEmptyTrace {
+		if e.EncodeTrace != nil && entry.TraceID != "" {
+			entryMap[e.TraceKey] = e.EncodeTrace(entry.TraceID)
+		} else {
+			entryMap[e.TraceKey] = entry.TraceID
+		}
+	}
+	if entry.SpanID != "" {
+		entryMap[e.SpanKey] = entry.SpanID
+	}
+	if e.EncodeCaller != nil {
+		entryMap[e.CallerKey] = e.EncodeCaller(entry.Caller)
+	} else if e.CallerLineKey != "" {
+		file, line, function := splitCaller(entry.Caller)
+		entryMap[e.CallerKey] = file
+		entryMap[e.CallerLineKey] = line
+		if e.CallerFunctionKey != "" && function != "" {
+			entryMap[e.CallerFunctionKey] = function
+		}
+	} else {
+		entryMap[e.CallerKey] = entry.Caller
+	}
+	if entry.Message != "" {
+		entryMap[e.MessageKey] = entry.Message
+	}
+
+	for _, field := range entry.Fields {
+		entryMap[field.Key] = field.Value
+	}
+
+	b, err := json.Marshal(entryMap)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// splitCaller splits "file:line function" into its parts
+func splitCaller(caller string) (string, int, string) {
+	caller, function, _ := strings.Cut(caller, " ")
+	idx := strings.LastIndex(caller, ":")
+	if idx == -1 {
+		return caller, 0, function
+	}
+	line, err := strconv.Atoi(caller[idx+1:])
+	if err != nil {
+		return caller, 0, function
+	}
+	return caller[:idx], line, function
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestJSONEncoders(t *testing.T) {
+	entry := &Entry{
+		Time:    time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC),
+		Level:   log.LevelWarn,
+		TraceID: "trace",
+		Caller:  "service/user.go:42 service.(*User).Get",
+		Message: "hello",
+	}
+	tests := []struct {
+		name    string
+		encoder *JSONEncoder
+		want    map[string]interface{}
+	}{
+		{
+			name:    "default",
+			encoder: NewJSONEncoder(),
+			want: map[string]interface{}{
+				TimeKey:   "2024-05-01T10:00:00.123456789Z",
+				LevelKey:  "WARN",
+				TraceKey:  "trace",
+				CallerKey: "service/user.go:42 service.(*User).Get",
+				MsgKey:    "hello",
+			},
+		},
+		{
+			name:    "ecs",
+			encoder: NewECSEncoder(),
+			want: map[string]interface{}{
+				"@timestamp":           "2024-05-01T10:00:00.123456789Z",
+				"log.level":            "warn",
+				"trace.id":             "trace",
+				"log.origin.file.name": "service/user.go",
+				"log.origin.file.line": float64(42),
+				"log.origin.function":  "service.(*User).Get",
+				"message":              "hello",
+				"ecs.version":          ecsVersion,
+			},
+		},
+		{
+			name:    "gcp",
+			encoder: NewGCPEncoder("project"),
+			want: map[string]interface{}{
+				"timestamp":  "2024-05-01T10:00:00.123456789Z",
+				"severity":   "WARNING",
+				gcpTraceKey:  "projects/project/traces/trace",
+				gcpSourceKey: map[string]interface{}{"file": "service/user.go", "line": float64(42), "function": "service.(*User).Get"},
+				"message":    "hello",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.encoder.Encode(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]interface{}
+			if err = json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("Encode() = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	entry := &Entry{
+		Time:    time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC),
+		Level:   log.LevelInfo,
+		Caller:  "user.go:42",
+		Message: "hello world",
+	}
+	b, err := NewLogfmtEncoder().Encode(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), TimeKey+"=2024-05-01T10:00:00.123456789Z ") {
+		t.Errorf("Encode() = %s", b)
+	}
+}
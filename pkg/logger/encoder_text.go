@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	colorReset   = "\x1b[0m"
+	colorGray    = "\x1b[90m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+)
+
+// LogfmtEncoder writes an entry as space separated key=value pairs
+type LogfmtEncoder struct {
+	// TimeFormat is a time layout, defaults to time.RFC3339Nano
+	TimeFormat string
+}
+
+func NewLogfmtEncoder() *LogfmtEncoder {
+	return &LogfmtEncoder{TimeFormat: time.RFC3339Nano}
+}
+
+func (e *LogfmtEncoder) Encode(entry *Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	writeLogfmt(&buf, TimeKey, entry.Time.Format(getTimeFormat(e.TimeFormat, time.RFC3339Nano)))
+	writeLogfmt(&buf, LevelKey, entry.Level.String())
+	writeLogfmt(&buf, TraceKey, entry.TraceID)
+	if entry.SpanID != "" {
+		writeLogfmt(&buf, SpanKey, entry.SpanID)
+	}
+	writeLogfmt(&buf, CallerKey, entry.Caller)
+	if entry.Message != "" {
+		writeLogfmt(&buf, MsgKey, entry.Message)
+	}
+	for _, field := range entry.Fields {
+		writeLogfmt(&buf, field.Key, formatValue(field.Value))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// ConsoleEncoder writes human friendly lines for local development:
+//
+//	15:04:05.000 INFO  caller message key=value trace_id=...
+type ConsoleEncoder struct {
+	// TimeFormat is a time layout, defaults to 15:04:05.000
+	TimeFormat string
+	// Color highlights the levels and the keys with ANSI escape codes
+	Color bool
+}
+
+func NewConsoleEncoder(color bool) *ConsoleEncoder {
+	return &ConsoleEncoder{
+		TimeFormat: "15:04:05.000",
+		Color:      color,
+	}
+}
+
+func (e *ConsoleEncoder) Encode(entry *Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(e.colorize(colorGray, entry.Time.Format(getTimeFormat(e.TimeFormat, "15:04:05.000"))))
+	buf.WriteByte(' ')
+	buf.WriteString(e.colorize(levelColor(entry.Level), fmt.Sprintf("%-5s", entry.Level.String())))
+	buf.WriteByte(' ')
+	buf.WriteString(e.colorize(colorGray, entry.Caller))
+	if entry.Message != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(entry.Message)
+	}
+	for _, field := range entry.Fields {
+		buf.WriteByte(' ')
+		buf.WriteString(e.colorize(colorCyan, field.Key+"="))
+		buf.WriteString(quoteIfNeeded(formatValue(field.Value)))
+	}
+	if entry.TraceID != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(e.colorize(colorGray, TraceKey+"="+entry.TraceID))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func (e *ConsoleEncoder) colorize(color, s string) string {
+	if !e.Color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func levelColor(level log.Level) string {
+	switch level {
+	case log.LevelDebug:
+		return colorMagenta
+	case log.LevelInfo:
+		return colorBlue
+	case log.LevelWarn:
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
+
+func writeLogfmt(buf *bytes.Buffer, key, value string) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(quoteIfNeeded(value))
+}
+
+// formatValue renders scalars with fmt and everything else as JSON
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case fmt.Stringer:
+		return val.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(val)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return string(b)
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func getTimeFormat(format, fallback string) string {
+	if format == "" {
+		return fallback
+	}
+	return format
+}
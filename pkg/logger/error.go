@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	errorMessageSuffix = ".message"
+	errorTypeSuffix    = ".type"
+	errorChainSuffix   = ".chain"
+	errorStackSuffix   = ".stack"
+)
+
+// stackTracer is implemented by errors carrying the stack trace of their creation,
+// like the ones of pkg/errors
+type stackTracer interface {
+	StackTrace() []string
+}
+
+// addErrorFields logs err under key as <key>.message, <key>.type (of the root cause),
+// <key>.chain (the messages of the wrapped errors) and <key>.stack (the deepest stack trace)
+func (r *redactor) addErrorFields(entry *Entry, key string, err error) {
+	if key == "err" {
+		key = ErrorKey
+	}
+	chain := unwrapChain(err)
+	messages := make([]string, 0, len(chain))
+	var stack []string
+	for _, e := range chain {
+		messages = append(messages, r.redactString(e.Error()))
+		if tracer, ok := e.(stackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+	}
+
+	entry.add(key+errorMessageSuffix, r.redactString(err.Error()))
+	entry.add(key+errorTypeSuffix, fmt.Sprintf("%T", chain[len(chain)-1]))
+	if len(chain) > 1 {
+		entry.add(key+errorChainSuffix, messages)
+	}
+	if stack != nil {
+		entry.add(key+errorStackSuffix, stack)
+	}
+}
+
+// unwrapChain returns err followed by the errors it wraps, depth first
+func unwrapChain(err error) []error {
+	chain := []error{err}
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range e.Unwrap() {
+			if wrapped != nil {
+				chain = append(chain, unwrapChain(wrapped)...)
+			}
+		}
+	default:
+		if wrapped := errors.Unwrap(err); wrapped != nil {
+			chain = append(chain, unwrapChain(wrapped)...)
+		}
+	}
+	return chain
+}
@@ -0,0 +1,31 @@
+package logger
+
+import "context"
+
+// MissingValue pads the last key given to WithFields without a value
+const MissingValue = "!MISSING"
+
+type fieldsContextKey struct{}
+
+// WithFields returns a copy of ctx carrying keyvals, every logger created from it by
+// NewLogger or NewLoggerWith includes them. Keys already in ctx are overridden and a
+// dangling key gets MissingValue, so it does not shift the pairs logged after it.
+func WithFields(ctx context.Context, keyvals ...interface{}) context.Context {
+	if len(keyvals) == 0 {
+		return ctx
+	}
+	fields := GetFields(ctx)
+	merged := make([]interface{}, 0, len(fields)+len(keyvals)+1)
+	merged = append(merged, fields...)
+	merged = append(merged, keyvals...)
+	if len(keyvals)%2 != 0 {
+		merged = append(merged, MissingValue)
+	}
+	return context.WithValue(ctx, fieldsContextKey{}, merged)
+}
+
+// GetFields returns the key/value pairs attached to ctx with WithFields
+func GetFields(ctx context.Context) []interface{} {
+	fields, _ := ctx.Value(fieldsContextKey{}).([]interface{})
+	return fields
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestWithFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		parent  []interface{}
+		keyvals []interface{}
+		want    []interface{}
+	}{
+		{name: "no fields", keyvals: nil, want: nil},
+		{name: "merges with the parent fields", parent: []interface{}{"a", 1}, keyvals: []interface{}{"b", 2}, want: []interface{}{"a", 1, "b", 2}},
+		{name: "pads a dangling key", keyvals: []interface{}{"a", 1, "b"}, want: []interface{}{"a", 1, "b", MissingValue}},
+		{name: "keeps the parent pairs aligned", parent: []interface{}{"a"}, keyvals: []interface{}{"b", 2}, want: []interface{}{"a", MissingValue, "b", 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.parent != nil {
+				ctx = WithFields(ctx, tt.parent...)
+			}
+			if got := GetFields(WithFields(ctx, tt.keyvals...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFieldsLogged(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Config{}) })
+	tests := []struct {
+		name   string
+		fields []interface{}
+		want   map[string]interface{}
+	}{
+		{
+			name:   "overrides the parent fields",
+			fields: []interface{}{"a", "parent", "b", "parent", "a", "child"},
+			want:   map[string]interface{}{"a": "child", "b": "parent", MsgKey: "hello"},
+		},
+		{
+			name:   "keeps the message after a dangling key",
+			fields: []interface{}{"a"},
+			want:   map[string]interface{}{"a": MissingValue, MsgKey: "hello"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Configure(Config{Outputs: []io.Writer{&out}}); err != nil {
+				t.Fatal(err)
+			}
+			NewLogger(WithFields(context.Background(), tt.fields...)).Info("hello")
+			var got map[string]interface{}
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s = %v, want %v", key, got[key], want)
+				}
+			}
+		})
+	}
+}
@@ -2,13 +2,11 @@ package logger
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/google/uuid"
-	"os"
-	"path/filepath"
-	"runtime"
+	"go.opentelemetry.io/otel/trace"
+	"io"
 	"strings"
 	"time"
 )
@@ -26,93 +24,117 @@ func NewBackgroundContextWithTraceID(serviceName string) context.Context {
 	return NewContextWithTraceID(context.Background())
 }
 
+// GetTraceIDs returns the ids of the OpenTelemetry span in ctx when there is one,
+// otherwise the trace ID stored under TraceKey
+func GetTraceIDs(ctx context.Context) (traceID string, spanID string) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		return spanContext.TraceID().String(), spanContext.SpanID().String()
+	}
+	traceID, _ = ctx.Value(TraceKey).(string)
+	return traceID, ""
+}
+
 func NewLogger(ctx context.Context) *log.Helper {
-	traceID, _ := ctx.Value(TraceKey).(string)
+	traceID, spanID := GetTraceIDs(ctx)
 	logger := NewJSONLogger(traceID, defaultCallerDepth)
+	logger.SpanID = spanID
+	logger.Fields = GetFields(ctx)
 	return log.NewHelper(logger)
 }
 
 func NewJSONLogger(traceID string, depth int) *JSONLogger {
 	return &JSONLogger{
-		Logger:  log.NewStdLogger(os.Stdout),
 		TraceID: traceID,
 		Depth:   depth,
 	}
 }
 
 func NewLoggerWith(ctx context.Context, keyvals ...interface{}) *log.Helper {
-	traceID, _ := ctx.Value(TraceKey).(string)
+	traceID, spanID := GetTraceIDs(ctx)
 
 	// Sửa: Dùng defaultCallerDepth + 1 (vì có thêm 1 lớp log.With)
 	rawLogger := NewJSONLogger(traceID, defaultCallerDepth+1)
+	rawLogger.SpanID = spanID
+	rawLogger.Fields = GetFields(ctx)
 
 	loggerWithFields := log.With(rawLogger, keyvals...)
 	return log.NewHelper(loggerWithFields)
 }
 
-func getCallerInfo(depth int) string {
-	_, file, line, ok := runtime.Caller(depth)
-	if !ok {
-		return "unknown"
+func (l *JSONLogger) Log(level log.Level, keyvals ...interface{}) error {
+	s := getSettings()
+	if len(s.config.PackageLevels) == 0 && level < s.level {
+		return nil
 	}
-
-	// --- Phương pháp 1: Ưu tiên Production (Theo yêu cầu của bạn) ---
-	// Tìm "go/src/backend/"
-	const prodAnchor = "go/src/backend/"
-	idx := strings.Index(file, prodAnchor)
-	if idx != -1 {
-		// Cắt chuỗi để lấy mọi thứ SAU "go/src/backend/"
-		relativePath := file[idx+len(prodAnchor):]
-		return fmt.Sprintf("%s:%d", relativePath, line)
+	// Depth là số frame tối thiểu bị bỏ qua, các frame của logger và Kratos log cũng bị bỏ qua
+	frame, _ := s.getCaller(l.Depth)
+	if len(s.config.PackageLevels) > 0 && level < s.minLevel(getFuncPackage(frame.Function)) {
+		return nil
+	}
+	if s.sampler != nil && !s.sampler.allow(level, getMessage(keyvals)) {
+		return nil
 	}
 
-	// --- Phương pháp 2: Thử kiểu Local (Cách bình thường) ---
-	// (Chỉ chạy nếu Phương pháp 1 thất bại)
-	rootPath, err := filepath.Abs("")
-	if err == nil {
-		relativePath, err := filepath.Rel(rootPath, file)
-		if err == nil && !strings.HasPrefix(relativePath, "..") {
-			return fmt.Sprintf("%s:%d", relativePath, line)
-		}
+	// Thêm các trường cố định
+	entry := &Entry{
+		Time:    time.Now(),
+		Level:   level,
+		TraceID: l.TraceID,
+		SpanID:  l.SpanID,
+		Caller:  s.formatCaller(frame),
 	}
 
-	// --- Phương pháp 3: Fallback (Dự phòng) ---
-	// Nếu cả 2 đều thất bại, trả về tên file
-	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	// Các trường lấy từ context, keyvals có thể ghi đè
+	keyvals = append(l.Fields[:len(l.Fields):len(l.Fields)], keyvals...)
+	s.addKeyvals(entry, keyvals)
+	if l.Logger != nil {
+		// Logger nhận entry đã redact thay vì encoder
+		return l.Logger.Log(level, entry.keyvals()...)
+	}
+	return s.write(l.Output, entry)
 }
 
-func (l *JSONLogger) Log(level log.Level, keyvals ...interface{}) error {
-	entryMap := make(map[string]interface{})
-
-	// Thêm các trường cố định
-	entryMap[TimeKey] = time.Now().Format(time.RFC3339)
-	entryMap[LevelKey] = level.String()
-	entryMap[TraceKey] = l.TraceID
-	entryMap[CallerKey] = getCallerInfo(l.Depth)
-
-	// Lặp qua TẤT CẢ keyvals và thêm vào map
-	if len(keyvals) > 1 {
-		for i := 0; i < len(keyvals)-1; i += 2 {
-			key, ok := keyvals[i].(string)
-			if !ok {
-				continue
-			}
-			val := keyvals[i+1]
-
-			// Xử lý "msg" đặc biệt để đảm bảo nó là string
-			if key == MsgKey {
-				entryMap[MsgKey] = fmt.Sprintf("%v", val)
-			} else {
-				entryMap[key] = val
-			}
+// addKeyvals adds the redacted keyvals to entry
+func (s *settings) addKeyvals(entry *Entry, keyvals []interface{}) {
+	// Lặp qua TẤT CẢ keyvals và thêm vào entry
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		val := keyvals[i+1]
+
+		// Xử lý "msg" đặc biệt để đảm bảo nó là string
+		if key == MsgKey {
+			entry.Message = s.redactor.redactString(fmt.Sprintf("%v", val))
+		} else if err, isErr := val.(error); isErr && err != nil {
+			s.redactor.addErrorFields(entry, key, err)
+		} else {
+			entry.add(key, s.redactor.redactField(key, val))
 		}
 	}
+}
 
-	b, err := json.Marshal(entryMap)
+// write encodes entry to out or to the configured writer
+func (s *settings) write(out io.Writer, entry *Entry) error {
+	b, err := s.encoder.Encode(entry)
 	if err != nil {
 		return err
 	}
 
-	_, err = fmt.Fprintln(os.Stdout, string(b))
+	if out == nil {
+		out = s.writer
+	}
+	_, err = out.Write(b)
 	return err
 }
+
+func getMessage(keyvals []interface{}) string {
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		if key, ok := keyvals[i].(string); ok && key == MsgKey {
+			return fmt.Sprintf("%v", keyvals[i+1])
+		}
+	}
+	return ""
+}
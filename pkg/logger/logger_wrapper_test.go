@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	spanTraceID = "0102030405060708090a0b0c0d0e0f10"
+	spanSpanID  = "0102030405060708"
+)
+
+func withSpan(ctx context.Context) context.Context {
+	traceID, _ := trace.TraceIDFromHex(spanTraceID)
+	spanID, _ := trace.SpanIDFromHex(spanSpanID)
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+}
+
+func TestGetTraceIDs(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Config{}) })
+	tests := []struct {
+		name      string
+		span      bool
+		traceID   string
+		wantTrace string
+		wantSpan  string
+	}{
+		{name: "span wins over the trace key", span: true, traceID: "context", wantTrace: spanTraceID, wantSpan: spanSpanID},
+		{name: "trace key without a span", traceID: "context", wantTrace: "context"},
+		{name: "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.traceID != "" {
+				ctx = context.WithValue(ctx, TraceKey, tt.traceID)
+			}
+			if tt.span {
+				ctx = withSpan(ctx)
+			}
+			traceID, spanID := GetTraceIDs(ctx)
+			if traceID != tt.wantTrace || spanID != tt.wantSpan {
+				t.Errorf("GetTraceIDs() = %q, %q, want %q, %q", traceID, spanID, tt.wantTrace, tt.wantSpan)
+			}
+
+			var out bytes.Buffer
+			if err := Configure(Config{Outputs: []io.Writer{&out}}); err != nil {
+				t.Fatal(err)
+			}
+			NewLogger(ctx).Info("hello")
+			var entry map[string]interface{}
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry[TraceKey] != tt.wantTrace {
+				t.Errorf("logged %s = %v, want %q", TraceKey, entry[TraceKey], tt.wantTrace)
+			}
+			if spanID, _ := entry[SpanKey].(string); spanID != tt.wantSpan {
+				t.Errorf("logged %s = %q, want %q", SpanKey, spanID, tt.wantSpan)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	// RedactTagKey is the struct tag controlling how a field is logged:
+	// `log:"redact"` replaces the value with RedactedValue, `log:"mask"` keeps its last
+	// characters only and `log:"-"` drops the field
+	RedactTagKey   = "log"
+	RedactedValue  = "[REDACTED]"
+	maxRedactDepth = 16
+	maskVisible    = 4
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardNumberPattern = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+)
+
+// DefaultRedactedKeys are the key patterns redacted when Config.Redaction is nil
+var DefaultRedactedKeys = []string{
+	"passw(or)?d", "secret", "token", "authorization", "api[_-]?key", "private[_-]?key", "cookie", "credential",
+}
+
+type RedactionConfig struct {
+	// Keys are regular expressions matched case-insensitively against log keys, struct field
+	// names and map keys, the values of the matching ones are redacted
+	Keys []string
+	// DetectEmails masks the email addresses found in string values
+	DetectEmails bool
+	// DetectCardNumbers masks the payment card numbers found in string values. Any run of 13
+	// to 19 digits passing the Luhn check is masked, IDs, phone numbers and timestamps included,
+	// so it is off by default.
+	DetectCardNumbers bool
+}
+
+// DefaultRedactionConfig redacts DefaultRedactedKeys and email addresses
+func DefaultRedactionConfig() RedactionConfig {
+	return RedactionConfig{
+		Keys:         DefaultRedactedKeys,
+		DetectEmails: true,
+	}
+}
+
+type redactor struct {
+	keys              *regexp.Regexp
+	detectEmails      bool
+	detectCardNumbers bool
+}
+
+func newRedactor(cfg RedactionConfig) (*redactor, error) {
+	r := &redactor{
+		detectEmails:      cfg.DetectEmails,
+		detectCardNumbers: cfg.DetectCardNumbers,
+	}
+	if len(cfg.Keys) > 0 {
+		keys, err := regexp.Compile("(?i)(" + strings.Join(cfg.Keys, ")|(") + ")")
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction key pattern: %w", err)
+		}
+		r.keys = keys
+	}
+	return r, nil
+}
+
+// Redact returns a representation of v that is safe to log. Structs are converted to maps
+// keyed like encoding/json would, with the fields tagged for redaction masked or removed,
+// and the configured key patterns and value detectors applied.
+func Redact(v interface{}) interface{} {
+	return getSettings().redactor.redact(v)
+}
+
+func (r *redactor) redact(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if err, ok := v.(error); ok && !isMarshaler(reflect.TypeOf(v)) {
+		return r.redactString(err.Error())
+	}
+	return r.redactValue(reflect.ValueOf(v), 0)
+}
+
+// redactField redacts the value logged under key
+func (r *redactor) redactField(key string, v interface{}) interface{} {
+	if r.isSensitiveKey(key) {
+		return RedactedValue
+	}
+	return r.redact(v)
+}
+
+func (r *redactor) isSensitiveKey(key string) bool {
+	return r.keys != nil && r.keys.MatchString(key)
+}
+
+func (r *redactor) redactValue(v reflect.Value, depth int) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > maxRedactDepth {
+		return RedactedValue
+	}
+	if isMarshaler(v.Type()) {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return nil
+		}
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return r.redactValue(v.Elem(), depth+1)
+	case reflect.Struct:
+		return r.redactStruct(v, depth)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		items := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items[i] = r.redactValue(v.Index(i), depth+1)
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		items := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := mapKey(iter.Key())
+			if r.isSensitiveKey(key) {
+				items[key] = RedactedValue
+				continue
+			}
+			items[key] = r.redactValue(iter.Value(), depth+1)
+		}
+		return items
+	case reflect.String:
+		return r.redactString(v.String())
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	default:
+		return v.Interface()
+	}
+}
+
+func (r *redactor) redactStruct(v reflect.Value, depth int) map[string]interface{} {
+	t := v.Type()
+	fields := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// the exported fields of an embedded unexported struct are promoted like encoding/json does
+		if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		name, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		switch field.Tag.Get(RedactTagKey) {
+		case "-":
+			continue
+		case "redact":
+			fields[name] = RedactedValue
+			continue
+		case "mask":
+			fields[name] = maskValue(fieldValue)
+			continue
+		}
+		// Embedded structs are flattened like encoding/json does, their name is not a key
+		flatten := field.Anonymous && field.Tag.Get("json") == ""
+		if !flatten && r.isSensitiveKey(name) {
+			fields[name] = RedactedValue
+			continue
+		}
+
+		value := r.redactValue(fieldValue, depth+1)
+		if embedded, ok := value.(map[string]interface{}); ok && flatten {
+			for k, val := range embedded {
+				if _, exists := fields[k]; !exists {
+					fields[k] = val
+				}
+			}
+			continue
+		}
+		fields[name] = value
+	}
+	return fields
+}
+
+// redactString masks the email addresses and card numbers found in s
+func (r *redactor) redactString(s string) string {
+	if r.detectEmails && strings.Contains(s, "@") {
+		s = emailPattern.ReplaceAllStringFunc(s, maskEmail)
+	}
+	if r.detectCardNumbers {
+		s = cardNumberPattern.ReplaceAllStringFunc(s, func(match string) string {
+			digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+			if !isLuhnValid(digits) {
+				return match
+			}
+			return maskString(digits)
+		})
+	}
+	return s
+}
+
+func maskValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return maskString(fmt.Sprintf("%v", v.Interface()))
+}
+
+// maskString keeps the last characters of s visible
+func maskString(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maskVisible {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-maskVisible) + string(runes[len(runes)-maskVisible:])
+}
+
+// maskEmail keeps the first character of the local part and the domain visible
+func maskEmail(email string) string {
+	local, domain, _ := strings.Cut(email, "@")
+	if len(local) <= 1 {
+		return "*@" + domain
+	}
+	return local[:1] + strings.Repeat("*", len(local)-1) + "@" + domain
+}
+
+func isLuhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+func mapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if k.Type().Implements(textMarshalerType) {
+		if b, err := k.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	b, _ := json.Marshal(k.Interface())
+	return strings.Trim(string(b), `"`)
+}
+
+func isMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
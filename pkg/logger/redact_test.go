@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Pin      string `json:"pin" log:"redact"`
+	Card     string `json:"card" log:"mask"`
+	Internal string `log:"-"`
+	Ignored  string `json:"-"`
+	Attempts int    `json:"attempts"`
+}
+
+type audited struct {
+	credentials
+	Note *string `json:"note"`
+}
+
+func TestRedactor(t *testing.T) {
+	r, err := newRedactor(DefaultRedactionConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	note := "mail john.doe@example.com"
+	tests := []struct {
+		name string
+		in   interface{}
+		want interface{}
+	}{
+		{name: "nil", in: nil, want: nil},
+		{name: "number", in: 42, want: 42},
+		{name: "plain string", in: "hello", want: "hello"},
+		{name: "email", in: "contact john.doe@example.com", want: "contact j*******@example.com"},
+		{name: "card number not detected by default", in: "id 4111111111111111", want: "id 4111111111111111"},
+		{name: "error message", in: errors.New("invalid email a@b.io"), want: "invalid email *@b.io"},
+		{
+			name: "tagged struct",
+			in:   credentials{Username: "john", Password: "p", Pin: "1234", Card: "4111111111111111", Internal: "x", Ignored: "y"},
+			want: map[string]interface{}{
+				"username": "john",
+				"password": RedactedValue,
+				"pin":      RedactedValue,
+				"card":     "************1111",
+				"attempts": 0,
+			},
+		},
+		{
+			name: "embedded struct",
+			in:   &audited{credentials: credentials{Username: "john", Attempts: 2}, Note: &note},
+			want: map[string]interface{}{
+				"username": "john",
+				"password": RedactedValue,
+				"pin":      RedactedValue,
+				"card":     "",
+				"attempts": 2,
+				"note":     "mail j*******@example.com",
+			},
+		},
+		{
+			name: "map keys",
+			in:   map[string]interface{}{"api_key": "k", "Authorization": "Bearer t", "name": "a"},
+			want: map[string]interface{}{"api_key": RedactedValue, "Authorization": RedactedValue, "name": "a"},
+		},
+		{
+			name: "slice",
+			in:   []interface{}{"a@b.io", map[string]string{"token": "t"}},
+			want: []interface{}{"*@b.io", map[string]interface{}{"token": RedactedValue}},
+		},
+		{name: "bytes", in: []byte("secret"), want: []byte("secret")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.redact(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redact() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactCardNumbers(t *testing.T) {
+	cfg := DefaultRedactionConfig()
+	cfg.DetectCardNumbers = true
+	r, err := newRedactor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "card 4111 1111 1111 1111", want: "card ************1111"},
+		{in: "card 4111-1111-1111-1111", want: "card ************1111"},
+		{in: "order 1234567890123", want: "order 1234567890123"},
+	}
+	for _, tt := range tests {
+		if got := r.redactString(tt.in); got != tt.want {
+			t.Errorf("redactString(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactField(t *testing.T) {
+	r, err := newRedactor(RedactionConfig{Keys: []string{"session"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key  string
+		in   interface{}
+		want interface{}
+	}{
+		{key: "session_id", in: "abc", want: RedactedValue},
+		{key: "password", in: "abc", want: "abc"},
+		{key: "email", in: "a@b.io", want: "a@b.io"},
+	}
+	for _, tt := range tests {
+		if got := r.redactField(tt.key, tt.in); got != tt.want {
+			t.Errorf("redactField(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestNewRedactorInvalidPattern(t *testing.T) {
+	if _, err := newRedactor(RedactionConfig{Keys: []string{"("}}); err == nil {
+		t.Error("newRedactor() accepted an invalid pattern")
+	}
+}
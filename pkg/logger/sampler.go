@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const samplerBuckets = 4096
+
+type SamplingConfig struct {
+	// Tick is the period the counters are reset after, defaults to one second
+	Tick time.Duration
+	// First entries with the same level and message are written every tick
+	First uint64
+	// Thereafter every Thereafter-th entry is written, zero drops all of them
+	Thereafter uint64
+}
+
+type samplerCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+type sampler struct {
+	config   SamplingConfig
+	counters [samplerBuckets]samplerCounter
+}
+
+func newSampler(cfg SamplingConfig) *sampler {
+	if cfg.Tick <= 0 {
+		cfg.Tick = time.Second
+	}
+	return &sampler{config: cfg}
+}
+
+// allow reports whether an entry with level and msg should be written
+func (s *sampler) allow(level log.Level, msg string) bool {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte{byte(level)})
+	_, _ = h.Write([]byte(msg))
+	counter := &s.counters[h.Sum32()%samplerBuckets]
+
+	now := time.Now().UnixNano()
+	resetAt := counter.resetAt.Load()
+	if now > resetAt && counter.resetAt.CompareAndSwap(resetAt, now+s.config.Tick.Nanoseconds()) {
+		counter.count.Store(0)
+	}
+
+	n := counter.count.Add(1)
+	if n <= s.config.First {
+		return true
+	}
+	return s.config.Thereafter > 0 && (n-s.config.First)%s.config.Thereafter == 0
+}
@@ -0,0 +1,193 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// SlogLevelFatal is the slog level of log.LevelFatal, slog has no fatal level
+const SlogLevelFatal = slog.LevelError + 4
+
+// SlogHandler is a slog.Handler writing the same entries as JSONLogger: trace ID from
+// the context, caller, level and the fields attached with WithFields, through the
+// encoder and the outputs set with Configure
+type SlogHandler struct {
+	// Output overrides the writer set with Configure
+	Output io.Writer
+	attrs  []interface{}
+	group  string
+}
+
+func NewSlogHandler() *SlogHandler {
+	return &SlogHandler{}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	s := getSettings()
+	// package levels are checked in Handle, once the caller is known
+	return len(s.config.PackageLevels) > 0 || fromSlogLevel(level) >= s.level
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	s := getSettings()
+	level := fromSlogLevel(record.Level)
+	if !s.enabled(level, record.PC) {
+		return nil
+	}
+	if s.sampler != nil && !s.sampler.allow(level, record.Message) {
+		return nil
+	}
+
+	traceID, spanID := GetTraceIDs(ctx)
+	entry := &Entry{
+		Time:    record.Time,
+		Level:   level,
+		TraceID: traceID,
+		SpanID:  spanID,
+		Caller:  s.formatCaller(getPCFrame(record.PC)),
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	fields := GetFields(ctx)
+	keyvals := make([]interface{}, 0, len(fields)+len(h.attrs)+2*record.NumAttrs()+2)
+	keyvals = append(keyvals, fields...)
+	keyvals = append(keyvals, h.attrs...)
+	keyvals = append(keyvals, MsgKey, record.Message)
+	record.Attrs(func(attr slog.Attr) bool {
+		keyvals = appendAttr(keyvals, h.group, attr)
+		return true
+	})
+	s.addKeyvals(entry, keyvals)
+	return s.write(h.Output, entry)
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	// clipped, the first append copies h.attrs instead of writing into its spare capacity,
+	// which would be shared with the other handlers derived from h
+	clone.attrs = slices.Clip(h.attrs)
+	for _, attr := range attrs {
+		clone.attrs = appendAttr(clone.attrs, h.group, attr)
+	}
+	return &clone
+}
+
+// WithGroup prefixes the keys of the attributes added later with "name."
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.group = h.group + name + "."
+	return &clone
+}
+
+// appendAttr appends attr to keyvals, groups are flattened into dotted keys
+func appendAttr(keyvals []interface{}, prefix string, attr slog.Attr) []interface{} {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return keyvals
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			keyvals = appendAttr(keyvals, prefix, groupAttr)
+		}
+		return keyvals
+	}
+	return append(keyvals, prefix+attr.Key, attr.Value.Any())
+}
+
+// SlogLogger is a Kratos log.Logger backed by a slog.Handler
+type SlogLogger struct {
+	Handler slog.Handler
+	// Ctx is passed to the handler, it carries the trace ID
+	Ctx   context.Context
+	Depth int
+}
+
+func NewSlogLogger(ctx context.Context, handler slog.Handler) *SlogLogger {
+	return &SlogLogger{
+		Handler: handler,
+		Ctx:     ctx,
+		Depth:   defaultCallerDepth,
+	}
+}
+
+// NewLoggerWithHandler is NewLogger for services logging through a slog.Handler
+func NewLoggerWithHandler(ctx context.Context, handler slog.Handler) *log.Helper {
+	return log.NewHelper(NewSlogLogger(ctx, handler))
+}
+
+func (l *SlogLogger) Log(level log.Level, keyvals ...interface{}) error {
+	slogLevel := toSlogLevel(level)
+	if !l.Handler.Enabled(l.Ctx, slogLevel) {
+		return nil
+	}
+
+	_, pc := getSettings().getCaller(l.Depth)
+
+	var msg string
+	attrs := make([]slog.Attr, 0, len(keyvals)/2)
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if key == MsgKey {
+			msg = fmt.Sprint(keyvals[i+1])
+			continue
+		}
+		attrs = append(attrs, slog.Any(key, keyvals[i+1]))
+	}
+
+	record := slog.NewRecord(time.Now(), slogLevel, msg, pc)
+	record.AddAttrs(attrs...)
+	return l.Handler.Handle(l.Ctx, record)
+}
+
+func getPCFrame(pc uintptr) runtime.Frame {
+	if pc == 0 {
+		return runtime.Frame{}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frame
+}
+
+func fromSlogLevel(level slog.Level) log.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return log.LevelDebug
+	case level < slog.LevelWarn:
+		return log.LevelInfo
+	case level < slog.LevelError:
+		return log.LevelWarn
+	case level < SlogLevelFatal:
+		return log.LevelError
+	default:
+		return log.LevelFatal
+	}
+}
+
+func toSlogLevel(level log.Level) slog.Level {
+	switch level {
+	case log.LevelDebug:
+		return slog.LevelDebug
+	case log.LevelInfo:
+		return slog.LevelInfo
+	case log.LevelWarn:
+		return slog.LevelWarn
+	case log.LevelError:
+		return slog.LevelError
+	default:
+		return SlogLevelFatal
+	}
+}
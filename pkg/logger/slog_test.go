@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestSlogHandlerWithAttrs(t *testing.T) {
+	var out bytes.Buffer
+	handler := NewSlogHandler()
+	handler.Output = &out
+	// a parent with spare capacity, so its children would share the array without clipping
+	parent := handler.WithAttrs([]slog.Attr{slog.String("service", "user")}).(*SlogHandler)
+	parent.attrs = append(make([]interface{}, 0, 16), parent.attrs...)
+	first := slog.New(parent.WithAttrs([]slog.Attr{slog.String("child", "first")}))
+	second := slog.New(parent.WithAttrs([]slog.Attr{slog.String("child", "second")}))
+
+	first.InfoContext(context.Background(), "hello")
+	second.InfoContext(context.Background(), "hello")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("written %d lines, want 2: %s", len(lines), out.String())
+	}
+	for i, want := range []string{`"child":"first"`, `"child":"second"`} {
+		if !strings.Contains(lines[i], want) || !strings.Contains(lines[i], `"service":"user"`) {
+			t.Errorf("line %d = %s, want %s", i, lines[i], want)
+		}
+	}
+}
+
+func TestSlogLevels(t *testing.T) {
+	for _, level := range []log.Level{log.LevelDebug, log.LevelInfo, log.LevelWarn, log.LevelError, log.LevelFatal} {
+		if got := fromSlogLevel(toSlogLevel(level)); got != level {
+			t.Errorf("round trip of %s = %s", level, got)
+		}
+	}
+	tests := []struct {
+		level slog.Level
+		want  log.Level
+	}{
+		{level: slog.LevelDebug - 4, want: log.LevelDebug},
+		{level: slog.LevelInfo + 2, want: log.LevelInfo},
+		{level: slog.LevelError + 2, want: log.LevelError},
+		{level: SlogLevelFatal + 4, want: log.LevelFatal},
+	}
+	for _, tt := range tests {
+		if got := fromSlogLevel(tt.level); got != tt.want {
+			t.Errorf("fromSlogLevel(%s) = %s, want %s", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSlogHandlerHandle(t *testing.T) {
+	tests := []struct {
+		name  string
+		level slog.Level
+		want  string
+	}{
+		{name: "info", level: slog.LevelInfo, want: "INFO"},
+		{name: "fatal", level: SlogLevelFatal, want: "FATAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			handler := NewSlogHandler()
+			handler.Output = &out
+			ctx := WithFields(context.WithValue(context.Background(), TraceKey, "trace"), "field", "f")
+			slog.New(handler).WithGroup("req").Log(ctx, tt.level, "hello", "id", 1, slog.Group("user", "name", "a"))
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{
+				LevelKey:        tt.want,
+				MsgKey:          "hello",
+				TraceKey:        "trace",
+				"field":         "f",
+				"req.id":        float64(1),
+				"req.user.name": "a",
+			}
+			for key, value := range want {
+				if entry[key] != value {
+					t.Errorf("%s = %v, want %v", key, entry[key], value)
+				}
+			}
+		})
+	}
+}
+
+type recordHandler struct {
+	ctx    context.Context
+	record slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler            { return h }
+func (h *recordHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.ctx, h.record = ctx, record
+	return nil
+}
+
+func TestSlogLoggerLog(t *testing.T) {
+	tests := []struct {
+		level log.Level
+		want  slog.Level
+	}{
+		{level: log.LevelDebug, want: slog.LevelDebug},
+		{level: log.LevelWarn, want: slog.LevelWarn},
+		{level: log.LevelFatal, want: SlogLevelFatal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			handler := &recordHandler{}
+			ctx := context.WithValue(context.Background(), TraceKey, "trace")
+			if err := NewSlogLogger(ctx, handler).Log(tt.level, MsgKey, "hello", "id", 1); err != nil {
+				t.Fatal(err)
+			}
+			if handler.ctx != ctx {
+				t.Error("the context was not passed to the handler")
+			}
+			if handler.record.Level != tt.want || handler.record.Message != "hello" {
+				t.Errorf("record = %s %q, want %s %q", handler.record.Level, handler.record.Message, tt.want, "hello")
+			}
+			var attrs []string
+			handler.record.Attrs(func(attr slog.Attr) bool {
+				attrs = append(attrs, attr.String())
+				return true
+			})
+			if strings.Join(attrs, ",") != "id=1" {
+				t.Errorf("attrs = %v, want [id=1]", attrs)
+			}
+			if handler.record.PC == 0 {
+				t.Error("record has no caller")
+			}
+		})
+	}
+}
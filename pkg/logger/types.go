@@ -1,22 +1,40 @@
 package logger
 
 import (
+	"io"
+
 	"github.com/go-kratos/kratos/v2/log"
 )
 
 const (
 	TraceKey           = "trace_id"
+	SpanKey            = "span_id"
 	TraceIDHeaderKey   = "X-Trace-ID"
 	InputKey           = "input"
 	CallerKey          = "caller"
 	TimeKey            = "time"
 	MsgKey             = "msg"
 	LevelKey           = "level"
+	UseCaseKey         = "usecase"
+	DurationKey        = "duration_ms"
+	ErrorKey           = "error"
+	UserIDKey          = "user_id"
+	TenantIDKey        = "tenant_id"
+	OperationKey       = "operation"
+	PathKey            = "path"
+	TableKey           = "table"
 	defaultCallerDepth = 3
 )
 
 type JSONLogger struct {
+	// Logger receives the entries instead of the encoder and the outputs, once filtered,
+	// sampled and redacted. Time and level are left to it.
 	Logger  log.Logger
 	TraceID string
+	SpanID  string
 	Depth   int // Thêm dòng này
+	// Fields are logged with every entry, before keyvals
+	Fields []interface{}
+	// Output overrides the writer set with Configure
+	Output io.Writer
 }
@@ -0,0 +1,217 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultAsyncBufferSize = 1024
+	backupTimeFormat       = "20060102T150405.000"
+)
+
+type AsyncConfig struct {
+	// BufferSize is the number of entries buffered, defaults to 1024
+	BufferSize int
+	// DropOnFull drops entries instead of blocking when the buffer is full
+	DropOnFull bool
+}
+
+// AsyncWriter writes to the underlying writer from a background goroutine
+type AsyncWriter struct {
+	out        io.Writer
+	entries    chan []byte
+	dropOnFull bool
+	done       chan struct{}
+	mu         sync.RWMutex
+	closed     bool
+	dropped    atomic.Uint64
+}
+
+func NewAsyncWriter(out io.Writer, cfg AsyncConfig) *AsyncWriter {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultAsyncBufferSize
+	}
+	w := &AsyncWriter{
+		out:        out,
+		entries:    make(chan []byte, cfg.BufferSize),
+		dropOnFull: cfg.DropOnFull,
+		done:       make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	for entry := range w.entries {
+		_, _ = w.out.Write(entry)
+	}
+}
+
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return w.out.Write(p)
+	}
+
+	// p may be reused by the caller once Write returns
+	entry := make([]byte, len(p))
+	copy(entry, p)
+	if !w.dropOnFull {
+		w.entries <- entry
+		return len(p), nil
+	}
+	select {
+	case w.entries <- entry:
+	default:
+		w.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of entries dropped because the buffer was full
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close writes the buffered entries, later writes go straight to the underlying writer
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.entries)
+	w.mu.Unlock()
+	<-w.done
+	return nil
+}
+
+type RotationConfig struct {
+	Filename string
+	// MaxSize is the size in bytes a file is rotated at, zero disables size rotation
+	MaxSize int64
+	// Interval rotates the file at every multiple of Interval, zero disables time rotation
+	Interval time.Duration
+	// MaxBackups is the number of rotated files kept, zero keeps all of them
+	MaxBackups int
+}
+
+// RotatingFile is a file writer renaming the current file to <name>-<time><ext>
+// when it reaches its size or time limit
+type RotatingFile struct {
+	mu       sync.Mutex
+	config   RotationConfig
+	file     *os.File
+	size     int64
+	rotateAt time.Time
+}
+
+func NewRotatingFile(cfg RotationConfig) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Filename), 0o755); err != nil {
+		return nil, err
+	}
+	f := &RotatingFile{config: cfg}
+	if err := f.open(time.Now()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	if f.shouldRotate(now, len(p)) {
+		if err := f.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+func (f *RotatingFile) shouldRotate(now time.Time, size int) bool {
+	if f.config.MaxSize > 0 && f.size > 0 && f.size+int64(size) > f.config.MaxSize {
+		return true
+	}
+	return f.config.Interval > 0 && !now.Before(f.rotateAt)
+}
+
+func (f *RotatingFile) open(now time.Time) error {
+	file, err := os.OpenFile(f.config.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	if f.config.Interval > 0 {
+		f.rotateAt = now.Truncate(f.config.Interval).Add(f.config.Interval)
+	}
+	return nil
+}
+
+func (f *RotatingFile) rotate(now time.Time) error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	prefix, ext := f.backupPrefix()
+	backup := prefix + now.Format(backupTimeFormat) + ext
+	if err := os.Rename(f.config.Filename, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := f.open(now); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// prune removes the oldest backups over MaxBackups
+func (f *RotatingFile) prune() error {
+	if f.config.MaxBackups <= 0 {
+		return nil
+	}
+	prefix, ext := f.backupPrefix()
+	backups, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return err
+	}
+	if len(backups) <= f.config.MaxBackups {
+		return nil
+	}
+	// the timestamp format sorts chronologically
+	sort.Strings(backups)
+	for _, backup := range backups[:len(backups)-f.config.MaxBackups] {
+		if err = os.Remove(backup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *RotatingFile) backupPrefix() (string, string) {
+	ext := filepath.Ext(f.config.Filename)
+	return strings.TrimSuffix(f.config.Filename, ext) + "-", ext
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	kratosErrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	UseCaseLabel   = "usecase"
+	TableLabel     = "table"
+	MethodLabel    = "method"
+	ClassLabel     = "class"
+	OperationLabel = "operation"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	UseCaseRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "usecase_requests_total",
+		Help: "Number of use case executions.",
+	}, []string{UseCaseLabel})
+	UseCaseErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "usecase_errors_total",
+		Help: "Number of failed use case executions by error class.",
+	}, []string{UseCaseLabel, ClassLabel})
+	UseCaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "usecase_duration_seconds",
+		Help:    "Use case execution latency.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{UseCaseLabel})
+
+	RepositoryQueries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "repository_operations_total",
+		Help: "Number of repository operations.",
+	}, []string{TableLabel, MethodLabel})
+	RepositoryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "repository_errors_total",
+		Help: "Number of failed repository operations by error class.",
+	}, []string{TableLabel, MethodLabel, ClassLabel})
+	RepositoryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "repository_duration_seconds",
+		Help:    "Repository operation latency.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{TableLabel, MethodLabel})
+	SlowQueries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "repository_slow_queries_total",
+		Help: "Number of queries slower than the configured threshold by SQL operation.",
+	}, []string{OperationLabel})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		UseCaseRequests,
+		UseCaseErrors,
+		UseCaseDuration,
+		RepositoryQueries,
+		RepositoryErrors,
+		RepositoryDuration,
+		SlowQueries,
+	)
+}
+
+// Registry returns the registry holding every metric of the package
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Handler exposes Registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ErrorClass maps err to a low cardinality label value. Errors can choose their class
+// by implementing ErrorClass() string.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	var classifier interface{ ErrorClass() string }
+	if errors.As(err, &classifier) {
+		return classifier.ErrorClass()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	case errors.Is(err, sql.ErrNoRows):
+		return "not_found"
+	}
+	var kratosErr *kratosErrors.Error
+	if errors.As(err, &kratosErr) {
+		// the reason is free text chosen by every service, only the code is bounded
+		return getCodeClass(int(kratosErr.Code))
+	}
+	return "internal"
+}
+
+func getCodeClass(code int) string {
+	switch code {
+	case http.StatusBadRequest:
+		return "invalid_argument"
+	case http.StatusUnauthorized:
+		return "unauthenticated"
+	case http.StatusForbidden:
+		return "permission_denied"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusTooManyRequests:
+		return "resource_exhausted"
+	case 499: // client closed request
+		return "canceled"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	case http.StatusGatewayTimeout:
+		return "deadline_exceeded"
+	}
+	if code >= 400 && code < 500 {
+		return "client_error"
+	}
+	return "internal"
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	kratosErrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+type classifiedError struct{}
+
+func (classifiedError) Error() string      { return "classified" }
+func (classifiedError) ErrorClass() string { return "custom" }
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "classifier", err: fmt.Errorf("wrap: %w", classifiedError{}), want: "custom"},
+		{name: "canceled", err: context.Canceled, want: "canceled"},
+		{name: "deadline", err: fmt.Errorf("wrap: %w", context.DeadlineExceeded), want: "deadline_exceeded"},
+		{name: "no rows", err: sql.ErrNoRows, want: "not_found"},
+		{name: "kratos not found", err: kratosErrors.NotFound("USER_NOT_FOUND_42", "user 42"), want: "not_found"},
+		{name: "kratos bad request", err: kratosErrors.BadRequest("ANY_REASON", ""), want: "invalid_argument"},
+		{name: "kratos other client error", err: kratosErrors.New(422, "UNPROCESSABLE", ""), want: "client_error"},
+		{name: "kratos server error", err: kratosErrors.InternalServer("DB_DOWN", ""), want: "internal"},
+		{name: "plain error", err: errors.New("boom"), want: "internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorClass(tt.err); got != tt.want {
+				t.Errorf("ErrorClass() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}